@@ -0,0 +1,138 @@
+// osf2fadein will convert an OSF file to Fade In.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	// Caltech Library Packages
+	"github.com/caltechlibrary/cli"
+
+	// My packages
+	"github.com/rsdoiel/osf"
+)
+
+var (
+	description = `osf2fadein is a command line program that reads an OSF XML file
+and writes a ".fadein" file. If the ".fadein" file already exists only
+its document.xml is replaced, images and other files in the archive are
+kept. Characters, locations and the like used in the screenplay are
+added to its lists so Fade In can suggest them.
+`
+
+	examples = `Convert *screenplay.osf* into *screenplay.fadein*.
+
+    osf2fadein -i screenplay.osf -o screenplay.fadein
+
+Round trip a Fade In project through OSF, keeping the rest of the archive
+
+    fadein2osf -i screenplay.fadein -o screenplay.osf
+    osf2fadein -i screenplay.osf -o screenplay.fadein
+`
+
+	// Standard Options
+	showHelp         bool
+	showLicense      bool
+	showVersion      bool
+	generateMarkdown bool
+	generateManPage  bool
+	quiet            bool
+	inputFName       string
+	outputFName      string
+)
+
+func main() {
+	app := cli.NewCli(osf.Version)
+
+	// Add Help
+	app.AddHelp("description", []byte(description))
+	app.AddHelp("examples", []byte(examples))
+
+	// Standard Options
+	app.BoolVar(&showHelp, "h,help", false, "display help")
+	app.BoolVar(&showLicense, "l,license", false, "display license")
+	app.BoolVar(&showVersion, "v,version", false, "display version")
+	app.BoolVar(&generateMarkdown, "generate-markdown", false, "generate Markdown documentation")
+	app.BoolVar(&generateManPage, "generate-manpage", false, "generate man page")
+	app.BoolVar(&quiet, "quiet", false, "suppress error messages")
+	app.StringVar(&inputFName, "i,input", "", "set the input filename")
+	app.StringVar(&outputFName, "o,output", "", "set the output filename")
+
+	// Parse environment and options
+	app.Parse()
+	args := app.Args()
+
+	// Setup IO
+	app.Eout = os.Stderr
+	app.Out = os.Stdout
+
+	// Process options
+	if generateMarkdown {
+		app.GenerateMarkdown(app.Out)
+		os.Exit(0)
+	}
+	if generateManPage {
+		app.GenerateManPage(app.Out)
+		os.Exit(0)
+	}
+	if showHelp {
+		if len(args) > 0 {
+			fmt.Fprintln(app.Out, app.Help(args...))
+		} else {
+			app.Usage(app.Out)
+		}
+		os.Exit(0)
+	}
+	if showLicense {
+		fmt.Fprintln(app.Out, app.License())
+		os.Exit(0)
+	}
+	if showVersion {
+		fmt.Fprintln(app.Out, app.Version())
+		os.Exit(0)
+	}
+
+	if inputFName == "" || inputFName == "-" {
+		fmt.Fprintln(app.Eout, "Missing an OSF filename, e.g. osf2fadein -i screenplay.osf -o screenplay.fadein")
+		os.Exit(1)
+	}
+	if outputFName == "" || outputFName == "-" {
+		fmt.Fprintln(app.Eout, "Missing a Fade In filename, e.g. osf2fadein -i screenplay.osf -o screenplay.fadein")
+		os.Exit(1)
+	}
+
+	screenplay, err := osf.ParseFile(inputFName)
+	cli.ExitOnError(app.Eout, err, quiet)
+	screenplay.RebuildLists()
+
+	err = osf.WriteFadeIn(outputFName, screenplay)
+	cli.ExitOnError(app.Eout, err, quiet)
+}
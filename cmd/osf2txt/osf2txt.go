@@ -55,6 +55,10 @@ and returns plain text
 Or alternatively
 
     cat screenplay.osf | osf2txt > screenplay.txt
+
+Lay out *screenplay.osf* on pages the way it would be printed.
+
+    osf2txt -layout -i screenplay.osf -o screenplay.txt
 `
 
 	// Standard Options
@@ -67,6 +71,9 @@ Or alternatively
 	quiet            bool
 	inputFName       string
 	outputFName      string
+
+	// App Options
+	layout bool
 )
 
 func main() {
@@ -87,6 +94,9 @@ func main() {
 	app.StringVar(&inputFName, "i,input", "", "set the input filename")
 	app.StringVar(&outputFName, "o,output", "", "set the output filename")
 
+	// App Options
+	app.BoolVar(&layout, "layout", false, "lay out the screenplay on pages with form feeds")
+
 	// Parse environment and options
 	app.Parse()
 	args := app.Args()
@@ -145,6 +155,9 @@ func main() {
 
 	// Create a string version of screenplay
 	s := screenplay.String()
+	if layout {
+		s = screenplay.ToText()
+	}
 
 	//and finally render the string version of the screenplay
 	if newLine {
@@ -0,0 +1,170 @@
+// osfdiff compares two drafts of an Open Screenplay Format 2.0 XML
+// document scene by scene.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	// Caltech Library Packages
+	"github.com/caltechlibrary/cli"
+
+	// My packages
+	"github.com/rsdoiel/osf"
+)
+
+var (
+	description = `osfdiff is a command line program that compares two drafts
+of a screenplay (osf, fadein or fdx files) scene by scene. Scenes are
+matched by their scene headings and the words they share rather than
+line by line, then reported as added, removed, moved or modified. For
+each modified scene the changed speeches of each character and the
+changed action are listed. The report is plain text by default, or
+JSON or an HTML page.
+`
+
+	examples = `List what changed between two drafts.
+
+    osfdiff first-draft.osf second-draft.osf
+
+Write the changes as an HTML page for the production office.
+
+    osfdiff -html -o changes.html first-draft.fadein second-draft.fadein
+
+Write the changes as JSON.
+
+    osfdiff -json first-draft.osf second-draft.osf
+`
+
+	// Standard Options
+	showHelp         bool
+	showLicense      bool
+	showVersion      bool
+	generateMarkdown bool
+	generateManPage  bool
+	newLine          bool
+	quiet            bool
+	outputFName      string
+
+	// App Options
+	asJSON bool
+	asHTML bool
+)
+
+func main() {
+	app := cli.NewCli(osf.Version)
+	app.SetParams("PREVIOUS", "CURRENT")
+
+	// Add Help
+	app.AddHelp("description", []byte(description))
+	app.AddHelp("examples", []byte(examples))
+
+	// Standard Options
+	app.BoolVar(&showHelp, "h,help", false, "display help")
+	app.BoolVar(&showLicense, "l,license", false, "display license")
+	app.BoolVar(&showVersion, "v,version", false, "display version")
+	app.BoolVar(&generateMarkdown, "generate-markdown", false, "generate Markdown documentation")
+	app.BoolVar(&generateManPage, "generate-manpage", false, "generate man page")
+	app.BoolVar(&newLine, "nl,newline", false, "add a trailing newline")
+	app.BoolVar(&quiet, "quiet", false, "suppress error messages")
+	app.StringVar(&outputFName, "o,output", "", "set the output filename")
+
+	// App Options
+	app.BoolVar(&asJSON, "json", false, "write the changes as JSON")
+	app.BoolVar(&asHTML, "html", false, "write the changes as an HTML page")
+
+	// Parse environment and options
+	app.Parse()
+	args := app.Args()
+
+	// Setup IO
+	var err error
+	app.Eout = os.Stderr
+	app.Out, err = cli.Create(outputFName, os.Stdout)
+	cli.ExitOnError(app.Eout, err, quiet)
+	defer cli.CloseFile(outputFName, app.Out)
+
+	// Process options
+	if generateMarkdown {
+		app.GenerateMarkdown(app.Out)
+		os.Exit(0)
+	}
+	if generateManPage {
+		app.GenerateManPage(app.Out)
+		os.Exit(0)
+	}
+	if showHelp {
+		if len(args) > 0 {
+			fmt.Fprintln(app.Out, app.Help(args...))
+		} else {
+			app.Usage(app.Out)
+		}
+		os.Exit(0)
+	}
+	if showLicense {
+		fmt.Fprintln(app.Out, app.License())
+		os.Exit(0)
+	}
+	if showVersion {
+		fmt.Fprintln(app.Out, app.Version())
+		os.Exit(0)
+	}
+	if len(args) != 2 {
+		cli.ExitOnError(app.Eout, fmt.Errorf("expected the previous and current drafts, see %s -help", app.AppName()), quiet)
+	}
+
+	// Read the two drafts
+	previous, err := osf.ParseFile(args[0])
+	cli.ExitOnError(app.Eout, err, quiet)
+	current, err := osf.ParseFile(args[1])
+	cli.ExitOnError(app.Eout, err, quiet)
+
+	diff, err := osf.Diff(previous, current)
+	cli.ExitOnError(app.Eout, err, quiet)
+	var out []byte
+	switch {
+	case asJSON:
+		out, err = json.MarshalIndent(diff, "", "    ")
+		cli.ExitOnError(app.Eout, err, quiet)
+	case asHTML:
+		out = []byte(diff.ToHTML())
+	default:
+		out = []byte(diff.String())
+	}
+
+	// and finally write the result
+	if newLine {
+		fmt.Fprintf(app.Out, "%s\n", out)
+	} else {
+		fmt.Fprintf(app.Out, "%s", out)
+	}
+}
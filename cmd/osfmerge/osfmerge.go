@@ -0,0 +1,186 @@
+// osfmerge merges the changes two drafts made to an Open Screenplay
+// Format 2.0 XML document.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	// Caltech Library Packages
+	"github.com/caltechlibrary/cli"
+
+	// My packages
+	"github.com/rsdoiel/osf"
+)
+
+var (
+	description = `osfmerge is a command line program that merges the changes
+two drafts (OURS and THEIRS) made to a screenplay since a common BASE
+draft. The script and title page are merged paragraph by paragraph,
+changes to different paragraphs are both kept. Where both drafts
+changed the same paragraphs differently both versions are written
+between paragraphs reading "<<<<<<< OURS", "=======" and ">>>>>>> THEIRS"
+and osfmerge exits with a status of one. Use -conflicts to also write
+the conflicts as JSON. The merged draft is written as osf, or as a
+Fade In file if the output filename ends in ".fadein".
+
+osfmerge can be used as a git merge driver for osf files, git passes
+the base, ours and theirs drafts and expects the result in ours.
+`
+
+	examples = `Merge the changes two writers made to *draft.osf*.
+
+    osfmerge -o merged.osf draft.osf anns-draft.osf bobs-draft.osf
+
+Use osfmerge to merge osf files in a git repository, add a merge
+driver to your git configuration
+
+    git config merge.osf.name "Open Screenplay Format merge"
+    git config merge.osf.driver "osfmerge -o %A %O %A %B"
+
+and use it for osf files in *.gitattributes*
+
+    *.osf merge=osf
+`
+
+	// Standard Options
+	showHelp         bool
+	showLicense      bool
+	showVersion      bool
+	generateMarkdown bool
+	generateManPage  bool
+	quiet            bool
+	outputFName      string
+
+	// App Options
+	conflictsFName string
+)
+
+func main() {
+	app := cli.NewCli(osf.Version)
+	app.SetParams("BASE", "OURS", "THEIRS")
+
+	// Add Help
+	app.AddHelp("description", []byte(description))
+	app.AddHelp("examples", []byte(examples))
+
+	// Standard Options
+	app.BoolVar(&showHelp, "h,help", false, "display help")
+	app.BoolVar(&showLicense, "l,license", false, "display license")
+	app.BoolVar(&showVersion, "v,version", false, "display version")
+	app.BoolVar(&generateMarkdown, "generate-markdown", false, "generate Markdown documentation")
+	app.BoolVar(&generateManPage, "generate-manpage", false, "generate man page")
+	app.BoolVar(&quiet, "quiet", false, "suppress error messages")
+	app.StringVar(&outputFName, "o,output", "", "set the output filename")
+
+	// App Options
+	app.StringVar(&conflictsFName, "conflicts", "", "write the conflicts as JSON to this file")
+
+	// Parse environment and options
+	app.Parse()
+	args := app.Args()
+
+	// Setup IO, the output is created after reading the drafts as it
+	// is often one of them
+	var err error
+	app.Eout = os.Stderr
+	app.Out = os.Stdout
+
+	// Process options
+	if generateMarkdown {
+		app.GenerateMarkdown(app.Out)
+		os.Exit(0)
+	}
+	if generateManPage {
+		app.GenerateManPage(app.Out)
+		os.Exit(0)
+	}
+	if showHelp {
+		if len(args) > 0 {
+			fmt.Fprintln(app.Out, app.Help(args...))
+		} else {
+			app.Usage(app.Out)
+		}
+		os.Exit(0)
+	}
+	if showLicense {
+		fmt.Fprintln(app.Out, app.License())
+		os.Exit(0)
+	}
+	if showVersion {
+		fmt.Fprintln(app.Out, app.Version())
+		os.Exit(0)
+	}
+	if len(args) != 3 {
+		cli.ExitOnError(app.Eout, fmt.Errorf("expected the base, ours and theirs drafts, see %s -help", app.AppName()), quiet)
+	}
+
+	// Read the three drafts
+	drafts := []*osf.OpenScreenplay{}
+	for _, fname := range args {
+		draft, err := osf.ParseFile(fname)
+		cli.ExitOnError(app.Eout, err, quiet)
+		drafts = append(drafts, draft)
+	}
+	merged, conflicts, err := osf.Merge(drafts[0], drafts[1], drafts[2])
+	cli.ExitOnError(app.Eout, err, quiet)
+
+	// Write the conflicts
+	if conflictsFName != "" {
+		src, err := json.MarshalIndent(conflicts, "", "    ")
+		cli.ExitOnError(app.Eout, err, quiet)
+		err = ioutil.WriteFile(conflictsFName, src, 0666)
+		cli.ExitOnError(app.Eout, err, quiet)
+	}
+
+	// and finally write the merged draft
+	if strings.ToLower(path.Ext(outputFName)) == ".fadein" {
+		err = osf.WriteFadeIn(outputFName, merged)
+		cli.ExitOnError(app.Eout, err, quiet)
+	} else {
+		src, err := merged.ToXML()
+		cli.ExitOnError(app.Eout, err, quiet)
+		app.Out, err = cli.Create(outputFName, os.Stdout)
+		cli.ExitOnError(app.Eout, err, quiet)
+		fmt.Fprintf(app.Out, "%s", src)
+		cli.CloseFile(outputFName, app.Out)
+	}
+	if len(conflicts) > 0 {
+		if !quiet {
+			fmt.Fprintf(app.Eout, "%d conflicts merging %s\n", len(conflicts), args[1])
+		}
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,209 @@
+// osfrevisions reports and manages the revisions of an Open Screenplay
+// Format 2.0 XML document.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	// Caltech Library Packages
+	"github.com/caltechlibrary/cli"
+
+	// My packages
+	"github.com/rsdoiel/osf"
+)
+
+var (
+	description = `osfrevisions is a command line program that reads an osf file
+and manages its revisions (e.g. those marked by Fade In). By
+default it lists the pages and scenes each revision changed. It can
+clear the revisions for a new draft, keep only the revisions from a
+given one on or write only the pages changed by a revision (and later
+ones) as plain text or PDF to hand out as revised pages. If no page
+was changed there is no PDF to write and it reports an error.
+`
+
+	examples = `List the pages and scenes changed by each revision of *screenplay.osf*.
+
+    osfrevisions -i screenplay.osf
+
+Start a new draft without any revisions.
+
+    osfrevisions -clear -i screenplay.osf -o new-draft.osf
+
+Keep only the revisions from the third (Yellow) on.
+
+    osfrevisions -keep 3 -i screenplay.osf -o yellow.osf
+
+Write the pages changed by the second (Pink) revision or later as a PDF.
+
+    osfrevisions -pages 2 -pdf -i screenplay.osf -o pink-pages.pdf
+`
+
+	// Standard Options
+	showHelp         bool
+	showLicense      bool
+	showVersion      bool
+	generateMarkdown bool
+	generateManPage  bool
+	newLine          bool
+	quiet            bool
+	inputFName       string
+	outputFName      string
+
+	// App Options
+	asJSON   bool
+	clearAll bool
+	keep     int
+	pages    int
+	asPDF    bool
+)
+
+func main() {
+	app := cli.NewCli(osf.Version)
+
+	// Add Help
+	app.AddHelp("description", []byte(description))
+	app.AddHelp("examples", []byte(examples))
+
+	// Standard Options
+	app.BoolVar(&showHelp, "h,help", false, "display help")
+	app.BoolVar(&showLicense, "l,license", false, "display license")
+	app.BoolVar(&showVersion, "v,version", false, "display version")
+	app.BoolVar(&generateMarkdown, "generate-markdown", false, "generate Markdown documentation")
+	app.BoolVar(&generateManPage, "generate-manpage", false, "generate man page")
+	app.BoolVar(&newLine, "nl,newline", false, "add a trailing newline")
+	app.BoolVar(&quiet, "quiet", false, "suppress error messages")
+	app.StringVar(&inputFName, "i,input", "", "set the input filename")
+	app.StringVar(&outputFName, "o,output", "", "set the output filename")
+
+	// App Options
+	app.BoolVar(&asJSON, "json", false, "list the changes as JSON")
+	app.BoolVar(&clearAll, "clear", false, "write the osf file without any revisions")
+	app.IntVar(&keep, "keep", 0, "write the osf file keeping this revision and later ones")
+	app.IntVar(&pages, "pages", 0, "write the pages changed by this revision or later ones")
+	app.BoolVar(&asPDF, "pdf", false, "write the changed pages as a PDF")
+
+	// Parse environment and options
+	app.Parse()
+	args := app.Args()
+
+	// Setup IO
+	var err error
+	app.Eout = os.Stderr
+	app.Out, err = cli.Create(outputFName, os.Stdout)
+	cli.ExitOnError(app.Eout, err, quiet)
+	defer cli.CloseFile(outputFName, app.Out)
+
+	// Process options
+	if generateMarkdown {
+		app.GenerateMarkdown(app.Out)
+		os.Exit(0)
+	}
+	if generateManPage {
+		app.GenerateManPage(app.Out)
+		os.Exit(0)
+	}
+	if showHelp {
+		if len(args) > 0 {
+			fmt.Fprintln(app.Out, app.Help(args...))
+		} else {
+			app.Usage(app.Out)
+		}
+		os.Exit(0)
+	}
+	if showLicense {
+		fmt.Fprintln(app.Out, app.License())
+		os.Exit(0)
+	}
+	if showVersion {
+		fmt.Fprintln(app.Out, app.Version())
+		os.Exit(0)
+	}
+
+	// Special case of input file is a .fadein, we use ParseFile...
+	var (
+		screenplay *osf.OpenScreenplay
+	)
+	if path.Ext(inputFName) != "" {
+		screenplay, err = osf.ParseFile(inputFName)
+		cli.ExitOnError(app.Eout, err, quiet)
+	} else {
+		app.In, err = cli.Open(inputFName, os.Stdin)
+		cli.ExitOnError(app.Eout, err, quiet)
+		defer cli.CloseFile(inputFName, app.In)
+		// ReadAll of input
+		src, err := ioutil.ReadAll(app.In)
+		cli.ExitOnError(app.Eout, err, quiet)
+		// Parse input
+		screenplay, err = osf.Parse(src)
+		cli.OnError(app.Eout, err, quiet)
+	}
+
+	var out []byte
+	switch {
+	case clearAll || keep > 0:
+		if clearAll {
+			screenplay.ClearRevisions()
+		} else {
+			screenplay.KeepRevisions(keep)
+		}
+		out, err = screenplay.ToXML()
+		cli.ExitOnError(app.Eout, err, quiet)
+	case pages > 0 && asPDF:
+		err = screenplay.ToRevisedPDF(app.Out, pages)
+		cli.ExitOnError(app.Eout, err, quiet)
+		os.Exit(0)
+	case pages > 0:
+		out = []byte(screenplay.ToRevisedText(pages))
+	case asJSON:
+		out, err = json.MarshalIndent(screenplay.RevisionChanges(), "", "    ")
+		cli.ExitOnError(app.Eout, err, quiet)
+	default:
+		src := []string{}
+		for _, changes := range screenplay.RevisionChanges() {
+			src = append(src, fmt.Sprintf("Revision %d (%s)\n    pages: %s\n    scenes: %s\n",
+				changes.Revision, changes.Color, strings.Join(changes.Pages, ", "), strings.Join(changes.Scenes, ", ")))
+		}
+		out = []byte(strings.Join(src, ""))
+	}
+
+	// and finally write the result
+	if newLine {
+		fmt.Fprintf(app.Out, "%s\n", out)
+	} else {
+		fmt.Fprintf(app.Out, "%s", out)
+	}
+}
@@ -46,7 +46,9 @@ import (
 
 var (
 	description = `txt2osf is a command line program that reads an plain text file
-and returns an OSF 2.0 text
+and returns an OSF 2.0 text. The lists of characters, locations,
+scene intros, scene times, extensions and transitions are filled in
+from the screenplay.
 `
 
 	examples = `Convert *screenplay.txt* into *screenplay.osf*.
@@ -56,6 +58,10 @@ and returns an OSF 2.0 text
 Or alternatively
 
     cat screenplay.txt | txt2osf > screenplay.osf
+
+Convert *screenplay.txt* for A4 paper.
+
+    txt2osf -a4 -i screenplay.txt -o screenplay.osf
 `
 
 	// Standard Options
@@ -68,6 +74,9 @@ Or alternatively
 	quiet            bool
 	inputFName       string
 	outputFName      string
+
+	// App Options
+	a4Paper bool
 )
 
 func main() {
@@ -88,6 +97,9 @@ func main() {
 	app.StringVar(&inputFName, "i,input", "", "set the input filename")
 	app.StringVar(&outputFName, "o,output", "", "set the output filename")
 
+	// App Options
+	app.BoolVar(&a4Paper, "a4", false, "lay out the screenplay for A4 paper instead of US Letter")
+
 	// Parse environment and options
 	app.Parse()
 	args := app.Args()
@@ -146,8 +158,14 @@ func main() {
 	}
 
 	// Create an OSF 2.0 version of screenplay
-	document = osf.NewOpenScreenplay20()
+	paper := osf.LetterPaper
+	if a4Paper {
+		paper = osf.A4Paper
+	}
+	document = osf.NewOpenScreenplay20(paper)
 	document.FromFountain(screenplay)
+	document.RebuildLists()
+	document.Paginate()
 	src, err := document.ToXML()
 	cli.OnError(app.Eout, err, quiet)
 
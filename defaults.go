@@ -0,0 +1,171 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// PaperSize is the paper a new screenplay is laid out for
+type PaperSize string
+
+const (
+	// LetterPaper is US Letter, 8.5 by 11 inches
+	LetterPaper PaperSize = "letter"
+	// A4Paper is A4, 210 by 297 millimetres
+	A4Paper PaperSize = "a4"
+)
+
+// builtinStyles are the screenplay styles Fade In writes, they are the
+// style sheet of new documents and are used when a document doesn't
+// define a style (e.g. one created by FromFountain). Indents are from the
+// margins in tenths of a millimetre. Normal Text to Shot are as seen in
+// testdata/OSF-2.0.xml. Cast List and Singing aren't in any of the test
+// documents, Cast List is set like Action and Singing like Dialogue in
+// italics.
+var builtinStyles = []*Style{
+	{Name: GeneralType, Builtin: "1", BuiltinIndex: "0", Label: GeneralType, Font: "Courier Screenplay", Size: "12"},
+	{Name: SceneHeadingType, Builtin: "1", BuiltinIndex: "1", Label: SceneHeadingType, BaseStyleName: GeneralType, StyleEnter: ActionType,
+		Font: "Courier Screenplay", Size: "12", SpaceBefore: "2.0", KeepWithNext: "1", AllCaps: AllCapsStyle},
+	{Name: ActionType, Builtin: "1", BuiltinIndex: "2", Label: ActionType, BaseStyleName: GeneralType,
+		StyleTabBefore: CharacterType, StyleTabAfter: CharacterType,
+		Font: "Courier Screenplay", Size: "12", SpaceBefore: "1.0"},
+	{Name: CharacterType, Builtin: "1", BuiltinIndex: "3", Label: CharacterType, BaseStyleName: GeneralType, StyleEnter: DialogueType,
+		StyleTabBefore: ActionType, StyleTabAfter: ActionType,
+		Font: "Courier Screenplay", Size: "12", SpaceBefore: "1.0", KeepWithNext: "1", LeftIdent: "635", AllCaps: AllCapsStyle},
+	{Name: ParentheticalType, Builtin: "1", BuiltinIndex: "4", Label: ParentheticalType, BaseStyleName: GeneralType, StyleEnter: DialogueType,
+		StyleTabBefore: DialogueType, StyleTabAfter: DialogueType,
+		Font: "Courier Screenplay", Size: "12", KeepWithNext: "1", LeftIdent: "508", RightIdent: "508"},
+	{Name: DialogueType, Builtin: "1", BuiltinIndex: "5", Label: DialogueType, BaseStyleName: GeneralType, StyleEnter: ActionType,
+		StyleTabBefore: ParentheticalType, StyleTabAfter: ParentheticalType,
+		Font: "Courier Screenplay", Size: "12", LeftIdent: "330", RightIdent: "254"},
+	{Name: TransitionType, Builtin: "1", BuiltinIndex: "6", Label: TransitionType, BaseStyleName: GeneralType, StyleEnter: SceneHeadingType,
+		Font: "Courier Screenplay", Size: "12", SpaceBefore: "1.0", Align: "right", LeftIdent: "1016", RightIdent: "127", AllCaps: AllCapsStyle},
+	{Name: ShotType, Builtin: "1", BuiltinIndex: "7", Label: ShotType, BaseStyleName: GeneralType, StyleEnter: ActionType,
+		Font: "Courier Screenplay", Size: "12", SpaceBefore: "1.0", KeepWithNext: "1", AllCaps: AllCapsStyle},
+	{Name: CastListType, Builtin: "1", BuiltinIndex: "8", Label: CastListType, BaseStyleName: GeneralType, StyleEnter: CastListType,
+		Font: "Courier Screenplay", Size: "12", SpaceBefore: "1.0"},
+	{Name: SingingType, Builtin: "1", BuiltinIndex: "9", Label: SingingType, BaseStyleName: GeneralType, StyleEnter: ActionType,
+		Font: "Courier Screenplay", Size: "12", LeftIdent: "330", RightIdent: "254", Italic: ItalicStyle},
+}
+
+// structuralStyles are the styles of section and boneyard paragraphs,
+// they are grey like the Heading style Fade In uses for notes in a
+// script. Fade In has no styles that aren't printed so it shows and
+// prints these paragraphs, the package's layout and renderers skip them
+// (see IsStructural).
+var structuralStyles = []*Style{
+	{Name: SectionType, Label: SectionType, BaseStyleName: GeneralType, StyleEnter: SceneHeadingType,
+		Font: "Courier Screenplay", Size: "12", SpaceBefore: "2.0", KeepWithNext: "1", Underline: "1", Color: "#7F7F7F"},
+	{Name: BoneyardType, Label: BoneyardType, BaseStyleName: GeneralType, StyleEnter: ActionType,
+		Font: "Courier Screenplay", Size: "12", SpaceBefore: "1.0", Italic: ItalicStyle, Color: "#7F7F7F"},
+}
+
+// NewStyles returns a copy of the built in style sheet, Normal Text,
+// Scene Heading, Action, Character, Parenthetical, Dialogue, Transition,
+// Shot, Cast List and Singing
+func NewStyles() *Styles {
+	styles := new(Styles)
+	for _, style := range builtinStyles {
+		s := *style
+		styles.Style = append(styles.Style, &s)
+	}
+	return styles
+}
+
+// NewSettings returns the settings Fade In uses for a new screenplay on
+// paper, one inch and a quarter margins at the top, left and (on US
+// Letter) right giving sixty characters to the line. Scenes and pages
+// aren't numbered or locked and there are no revisions.
+func NewSettings(paper PaperSize) *Settings {
+	settings := &Settings{
+		ContText:             "(cont'd)",
+		MoreText:             "(MORE)",
+		ContinuedText:        "CONTINUED",
+		OmittedText:          "OMITTED",
+		SceneTimeSeparator:   " - ",
+		PageHeader:           "#.",
+		SceneNumberFormat:    "#",
+		DialogueNumberFormat: "()",
+	}
+	settings.SetLinesPerInch(DefaultLinesPerInch)
+	settings.SetSpacing(1.0)
+	settings.SetHeaderAlign(RightAlignment)
+	settings.SetFooterAlign(RightAlignment)
+	settings.SetFirstPageNumber(1)
+	settings.SetFirstSceneNumber(1)
+	settings.SetFirstDialogueNumber(1)
+	settings.SetSceneNumberSides(true, true)
+	settings.SetRevisionNumber(0)
+	for flag, value := range map[Flag]bool{
+		BreakOnSentencesFlag:   true,
+		DialogueContinuesFlag:  true,
+		DialoguePageBreaksFlag: true,
+		ScenesContinueFlag:     false,
+		AutoOmitScenesFlag:     false,
+		HeaderFirstPageFlag:    false,
+		FooterFirstPageFlag:    true,
+		PagesLockedFlag:        false,
+		SceneNumberingFlag:     false,
+		SceneNumberSkipIOFlag:  false,
+		ScenesLockedFlag:       false,
+		DialogueNumberingFlag:  false,
+		DialogueLockedFlag:     false,
+		ShowRevisionsFlag:      true,
+		ShowAllRevisionsFlag:   true,
+	} {
+		settings.SetFlag(flag, value)
+	}
+	settings.SetPageMode(NumberingModeSuffix)
+	settings.SetSceneMode(NumberingModeSuffix)
+	if paper == A4Paper {
+		// A4 is narrower and taller, a smaller right margin keeps sixty
+		// characters to the line
+		settings.SetPageSize(A4Width, A4Height)
+		settings.SetMargins(DefaultMarginTop, DefaultMarginBottom, DefaultMarginLeft, A4Width-DefaultMarginLeft-6*Inch)
+	} else {
+		settings.SetPageSize(LetterWidth, LetterHeight)
+		settings.SetMargins(DefaultMarginTop, DefaultMarginBottom, DefaultMarginLeft, DefaultMarginRight)
+	}
+	return settings
+}
+
+// newUUID returns a random (version 4) UUID in upper case as Fade In
+// writes them, e.g. "9BCC72D3-5736-46A9-9BE6-44CAACDE65C1"
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%X-%X-%X-%X-%X", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,112 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestNewOpenScreenplay20(t *testing.T) {
+	document := NewOpenScreenplay20()
+	uuidRE := regexp.MustCompile(`^[0-9A-F]{8}-[0-9A-F]{4}-4[0-9A-F]{3}-[89AB][0-9A-F]{3}-[0-9A-F]{12}$`)
+	if document.Info == nil || !uuidRE.MatchString(document.Info.UUID) {
+		t.Fatalf("expected a UUID, got %+v", document.Info)
+	}
+	if other := NewOpenScreenplay20(); other.Info.UUID == document.Info.UUID {
+		t.Errorf("expected each document to get its own UUID")
+	}
+
+	// The style sheet matches the one Fade In writes
+	names := []string{}
+	for _, style := range document.Styles.Style {
+		names = append(names, style.Name)
+	}
+	expected := "Normal Text, Scene Heading, Action, Character, Parenthetical, Dialogue, Transition, Shot, Cast List, Singing"
+	if s := strings.Join(names, ", "); s != expected {
+		t.Errorf("expected styles %s, got %s", expected, s)
+	}
+	// Normal Text to Shot are as Fade In writes them in OSF-2.0.xml
+	fadeIn, err := ParseFile(filepath.Join("testdata", "OSF-2.0.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, style := range document.Styles.Style[:8] {
+		if other := fadeIn.findStyle(style.Name); other == nil || xmlKey(other) != xmlKey(style) {
+			t.Errorf("expected\n%s\ngot\n%s", xmlKey(other), xmlKey(style))
+		}
+	}
+	character := document.findStyle(CharacterType)
+	if character == nil || character.LeftIdent != "635" || character.AllCaps != AllCapsStyle || character.StyleEnter != DialogueType {
+		t.Errorf("unexpected Character style %+v", character)
+	}
+	// Changing a document's styles doesn't change the built in ones
+	character.LeftIdent = "0"
+	if style := NewStyles().Style[3]; style.LeftIdent != "635" {
+		t.Errorf("expected built in styles to be copied, got %+v", style)
+	}
+
+	settings := document.Settings
+	if width, height := settings.PageSize(); width != LetterWidth || height != LetterHeight {
+		t.Errorf("expected US Letter, got %d x %d", width, height)
+	}
+	if settings.MarginRight != "317" || settings.NormalLinesPerInch != "6.0" || settings.ContText != "(cont'd)" || settings.SceneTimeSeparator != " - " {
+		t.Errorf("unexpected settings %+v", settings)
+	}
+	if !settings.Flag(DialogueContinuesFlag) || settings.Flag(SceneNumberingFlag) || settings.Flag(PagesLockedFlag) || settings.RevisionNumber() != 0 {
+		t.Errorf("unexpected settings flags %+v", settings)
+	}
+
+	// A4 keeps the same sixty characters to the line
+	document = NewOpenScreenplay20(A4Paper)
+	settings = document.Settings
+	if width, height := settings.PageSize(); width != A4Width || height != A4Height {
+		t.Errorf("expected A4, got %d x %d", width, height)
+	}
+	top, bottom, left, right := settings.Margins()
+	if top != DefaultMarginTop || bottom != DefaultMarginBottom || left != DefaultMarginLeft || A4Width-left-right != 6*Inch {
+		t.Errorf("unexpected A4 margins %d %d %d %d", top, bottom, left, right)
+	}
+
+	// A new document round trips through XML
+	src, err := document.ToXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Info.UUID != document.Info.UUID || len(other.Styles.Style) != 10 || other.Settings.PageWidth != "2100" {
+		t.Errorf("expected a new document to round trip, got\n%s", src)
+	}
+}
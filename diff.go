@@ -0,0 +1,511 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The changes Diff reports for scenes and their paragraphs
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeMoved    = "moved"
+	ChangeModified = "modified"
+)
+
+// sceneMatchThreshold is the lowest similarity (see sceneSimilarity) two
+// scenes need to be treated as versions of the same scene
+const sceneMatchThreshold = 0.4
+
+// LineChange is a changed dialogue or action paragraph in a scene
+type LineChange struct {
+	// Character is the speaker of changed dialogue, it is empty for action
+	Character string `json:"character,omitempty" yaml:"character,omitempty"`
+	Change    string `json:"change" yaml:"change"`
+	Previous  string `json:"previous,omitempty" yaml:"previous,omitempty"`
+	Current   string `json:"current,omitempty" yaml:"current,omitempty"`
+}
+
+// SceneChange is a scene that was added, removed, moved or modified
+// between two drafts
+type SceneChange struct {
+	Change string `json:"change" yaml:"change"`
+	// Heading is the scene heading, for a removed scene it is the
+	// previous one. The text before the first scene heading is a scene
+	// without one.
+	Heading string `json:"heading" yaml:"heading"`
+	// PreviousHeading is set if the scene heading changed
+	PreviousHeading string `json:"previous_heading,omitempty" yaml:"previous_heading,omitempty"`
+	// Scene and PreviousScene are the scene's position in the current
+	// and previous drafts counting from one, zero if it isn't in the draft
+	Scene               int    `json:"scene,omitempty" yaml:"scene,omitempty"`
+	PreviousScene       int    `json:"previous_scene,omitempty" yaml:"previous_scene,omitempty"`
+	SceneNumber         string `json:"scene_number,omitempty" yaml:"scene_number,omitempty"`
+	PreviousSceneNumber string `json:"previous_scene_number,omitempty" yaml:"previous_scene_number,omitempty"`
+	// Modified is set if the text of a moved or modified scene changed
+	Modified bool `json:"modified,omitempty" yaml:"modified,omitempty"`
+	// Dialogue lists the changed speeches by character
+	Dialogue []*LineChange `json:"dialogue,omitempty" yaml:"dialogue,omitempty"`
+	// Action lists the other changed paragraphs (e.g. action, transitions)
+	Action []*LineChange `json:"action,omitempty" yaml:"action,omitempty"`
+}
+
+// ScreenplayDiff lists what changed between two drafts scene by scene
+type ScreenplayDiff struct {
+	// Scenes are the changed scenes in the order of the current draft,
+	// removed scenes are where they were
+	Scenes []*SceneChange `json:"scenes" yaml:"scenes"`
+	// Unchanged is the number of scenes that are the same in both drafts
+	Unchanged int `json:"unchanged" yaml:"unchanged"`
+}
+
+// diffScene is a scene being compared, it counts the words of the
+// scene's printed paragraphs
+type diffScene struct {
+	*Scene
+	position int
+	words    map[string]int
+	total    int
+}
+
+// headingKey returns the scene heading in upper case with its spacing
+// normalized
+func (scene *diffScene) headingKey() string {
+	return strings.Join(strings.Fields(strings.ToUpper(scene.HeadingText())), " ")
+}
+
+// printed returns the printed paragraphs of the scene after its heading
+func (scene *diffScene) printed() []*Para {
+	paras := []*Para{}
+	for _, para := range scene.Body {
+		if para.isPrinted() {
+			paras = append(paras, para)
+		}
+	}
+	return paras
+}
+
+// sameText reports if two scenes have the same heading and paragraphs
+func (scene *diffScene) sameText(other *diffScene) bool {
+	a, b := scene.printed(), other.printed()
+	if scene.HeadingText() != other.HeadingText() || len(a) != len(b) {
+		return false
+	}
+	for i, para := range a {
+		if paraStyleName(para) != paraStyleName(b[i]) || para.PlainText() != b[i].PlainText() {
+			return false
+		}
+	}
+	return true
+}
+
+// diffScenes returns the scenes of a document to compare
+func diffScenes(document *OpenScreenplay) []*diffScene {
+	scenes := []*diffScene{}
+	for i, scene := range document.Scenes() {
+		d := &diffScene{Scene: scene, position: i + 1, words: map[string]int{}}
+		for _, para := range d.printed() {
+			for _, word := range strings.Fields(strings.ToLower(para.PlainText())) {
+				d.words[word]++
+				d.total++
+			}
+		}
+		scenes = append(scenes, d)
+	}
+	return scenes
+}
+
+// sceneSimilarity scores how alike two scenes are from zero to one. The
+// words the scenes share count for 0.6 and the same scene heading for
+// 0.4, so scenes with the same heading are always matched and a scene
+// whose heading changed is matched if two thirds of its words are the same.
+func sceneSimilarity(a, b *diffScene) float64 {
+	score := 0.0
+	if a.total+b.total > 0 {
+		common := 0
+		for word, n := range a.words {
+			if m := b.words[word]; m < n {
+				common += m
+			} else {
+				common += n
+			}
+		}
+		score = 0.6 * 2 * float64(common) / float64(a.total+b.total)
+	}
+	if a.headingKey() == b.headingKey() {
+		score += 0.4
+	}
+	return score
+}
+
+// matchScenes pairs the scenes of two drafts, the most similar are paired
+// first. It returns the current scene paired with each previous one, or
+// -1 if it has none.
+func matchScenes(previous, current []*diffScene) []int {
+	type candidate struct {
+		i, j     int
+		score    float64
+		distance float64
+	}
+	candidates := []candidate{}
+	for i, a := range previous {
+		for j, b := range current {
+			if score := sceneSimilarity(a, b); score >= sceneMatchThreshold {
+				distance := float64(i)/float64(len(previous)) - float64(j)/float64(len(current))
+				if distance < 0 {
+					distance = -distance
+				}
+				candidates = append(candidates, candidate{i, j, score, distance})
+			}
+		}
+	}
+	// Ties, e.g. repeated scenes, are broken by keeping their place
+	sort.SliceStable(candidates, func(a, b int) bool {
+		if candidates[a].score != candidates[b].score {
+			return candidates[a].score > candidates[b].score
+		}
+		return candidates[a].distance < candidates[b].distance
+	})
+	pairs := make([]int, len(previous))
+	for i := range pairs {
+		pairs[i] = -1
+	}
+	paired := make([]bool, len(current))
+	for _, c := range candidates {
+		if pairs[c.i] < 0 && !paired[c.j] {
+			pairs[c.i] = c.j
+			paired[c.j] = true
+		}
+	}
+	return pairs
+}
+
+// sceneLines returns the speeches of each character in a scene, in the
+// order the characters first speak, and the text of its action
+func sceneLines(scene *Scene) ([]string, map[string][]string, []string) {
+	characters, speeches, action := []string{}, map[string][]string{}, []string{}
+	blocks, paras := scene.split()
+	for _, block := range blocks {
+		if _, ok := speeches[block.Character]; !ok {
+			characters = append(characters, block.Character)
+		}
+		speeches[block.Character] = append(speeches[block.Character], block.Text())
+	}
+	for _, para := range paras {
+		action = append(action, strings.TrimSpace(para.PlainText()))
+	}
+	return characters, speeches, action
+}
+
+// diffLines compares two lists of paragraph texts. Unchanged texts are
+// matched first, those between them are paired in order as modified and
+// what is left over was added or removed.
+func diffLines(previous, current []string, character string) []*LineChange {
+	changes := []*LineChange{}
+	matches := diffMatches(len(previous), len(current), func(i, j int) bool { return previous[i] == current[j] })
+	i, j := 0, 0
+	for _, match := range append(matches, [2]int{len(previous), len(current)}) {
+		for ; i < match[0] && j < match[1]; i, j = i+1, j+1 {
+			changes = append(changes, &LineChange{Character: character, Change: ChangeModified, Previous: previous[i], Current: current[j]})
+		}
+		for ; i < match[0]; i++ {
+			changes = append(changes, &LineChange{Character: character, Change: ChangeRemoved, Previous: previous[i]})
+		}
+		for ; j < match[1]; j++ {
+			changes = append(changes, &LineChange{Character: character, Change: ChangeAdded, Current: current[j]})
+		}
+		i, j = match[0]+1, match[1]+1
+	}
+	return changes
+}
+
+// compareScenes fills in the dialogue and action changes between two
+// versions of a scene
+func (change *SceneChange) compareScenes(previous, current *diffScene) {
+	previousCharacters, previousSpeeches, previousAction := sceneLines(previous.Scene)
+	currentCharacters, currentSpeeches, currentAction := sceneLines(current.Scene)
+	seen := map[string]bool{}
+	for _, character := range append(currentCharacters, previousCharacters...) {
+		if !seen[character] {
+			seen[character] = true
+			change.Dialogue = append(change.Dialogue, diffLines(previousSpeeches[character], currentSpeeches[character], character)...)
+		}
+	}
+	change.Action = diffLines(previousAction, currentAction, "")
+}
+
+// Diff compares two drafts of a screenplay scene by scene. Scenes are
+// matched by their scene headings and the words they share, then
+// reported as added, removed, moved (out of order with the scenes around
+// them) or modified. Within a matched scene the changed speeches of each
+// character and the changed action are listed.
+func Diff(previous, current *OpenScreenplay) (*ScreenplayDiff, error) {
+	if previous == nil || current == nil {
+		return nil, fmt.Errorf("two drafts are needed to compare")
+	}
+	previousScenes, currentScenes := diffScenes(previous), diffScenes(current)
+	pairs := matchScenes(previousScenes, currentScenes)
+
+	// The pairs that keep their order are the longest common subsequence
+	// of the paired scenes in previous and current order, the rest moved
+	byPrevious, byCurrent := []int{}, make([]int, 0, len(pairs))
+	pairedWith := make([]int, len(currentScenes))
+	for j := range pairedWith {
+		pairedWith[j] = -1
+	}
+	for i, j := range pairs {
+		if j >= 0 {
+			byPrevious = append(byPrevious, i)
+			pairedWith[j] = i
+		}
+	}
+	for _, i := range pairedWith {
+		if i >= 0 {
+			byCurrent = append(byCurrent, i)
+		}
+	}
+	moved := map[int]bool{}
+	for _, i := range byPrevious {
+		moved[i] = true
+	}
+	for _, match := range diffMatches(len(byPrevious), len(byCurrent), func(a, b int) bool { return byPrevious[a] == byCurrent[b] }) {
+		delete(moved, byPrevious[match[0]])
+	}
+
+	// Removed scenes are reported after the scene that followed the
+	// scene before them
+	removedAfter := map[int][]*SceneChange{}
+	after := -1
+	for i, j := range pairs {
+		if j >= 0 {
+			after = j
+			continue
+		}
+		scene := previousScenes[i]
+		removedAfter[after] = append(removedAfter[after], &SceneChange{
+			Change:              ChangeRemoved,
+			Heading:             scene.HeadingText(),
+			PreviousScene:       scene.position,
+			PreviousSceneNumber: scene.Number,
+		})
+	}
+
+	diff := &ScreenplayDiff{Scenes: removedAfter[-1]}
+	for j, scene := range currentScenes {
+		i := pairedWith[j]
+		change := &SceneChange{
+			Change:      ChangeAdded,
+			Heading:     scene.HeadingText(),
+			Scene:       scene.position,
+			SceneNumber: scene.Number,
+		}
+		if i >= 0 {
+			previousScene := previousScenes[i]
+			change.PreviousScene = previousScene.position
+			change.PreviousSceneNumber = previousScene.Number
+			change.Modified = !previousScene.sameText(scene)
+			switch {
+			case moved[i]:
+				change.Change = ChangeMoved
+			case change.Modified:
+				change.Change = ChangeModified
+			default:
+				diff.Unchanged++
+				change = nil
+			}
+			if change != nil && change.Modified {
+				if heading := previousScene.HeadingText(); heading != change.Heading {
+					change.PreviousHeading = heading
+				}
+				change.compareScenes(previousScene, scene)
+			}
+		}
+		if change != nil {
+			diff.Scenes = append(diff.Scenes, change)
+		}
+		diff.Scenes = append(diff.Scenes, removedAfter[j]...)
+	}
+	return diff, nil
+}
+
+// sceneLabel names a scene by its number, or its position if scenes
+// aren't numbered
+func sceneLabel(number string, position int) string {
+	if number != "" {
+		return "scene " + number
+	}
+	return "scene " + strconv.Itoa(position)
+}
+
+// summary describes a scene change in a line, e.g. "Moved scene 3 (was
+// scene 5): INT. KITCHEN - DAY"
+func (change *SceneChange) summary() string {
+	heading := change.Heading
+	if heading == "" {
+		heading = "(before the first scene heading)"
+	}
+	switch change.Change {
+	case ChangeRemoved:
+		return fmt.Sprintf("Removed %s: %s", sceneLabel(change.PreviousSceneNumber, change.PreviousScene), heading)
+	case ChangeAdded:
+		return fmt.Sprintf("Added %s: %s", sceneLabel(change.SceneNumber, change.Scene), heading)
+	}
+	s := "Modified"
+	if change.Change == ChangeMoved {
+		s = "Moved"
+		if change.Modified {
+			s = "Moved and modified"
+		}
+	}
+	label, previousLabel := sceneLabel(change.SceneNumber, change.Scene), sceneLabel(change.PreviousSceneNumber, change.PreviousScene)
+	if label != previousLabel {
+		return fmt.Sprintf("%s %s (was %s): %s", s, label, previousLabel, heading)
+	}
+	return fmt.Sprintf("%s %s: %s", s, label, heading)
+}
+
+// label names who or what a line change is about
+func (change *LineChange) label() string {
+	if change.Character == "" {
+		return "action"
+	}
+	return change.Character
+}
+
+// String renders the changes as a plain text report, one line for each
+// changed scene followed by its changed headings, speeches and action
+// indented below it
+func (diff *ScreenplayDiff) String() string {
+	if len(diff.Scenes) == 0 {
+		return "No scenes changed\n"
+	}
+	var sb strings.Builder
+	for _, change := range diff.Scenes {
+		sb.WriteString(change.summary() + "\n")
+		if change.PreviousHeading != "" {
+			fmt.Fprintf(&sb, "    heading was: %s\n", change.PreviousHeading)
+		}
+		for _, line := range append(append([]*LineChange{}, change.Dialogue...), change.Action...) {
+			switch line.Change {
+			case ChangeAdded:
+				fmt.Fprintf(&sb, "    %s added: %q\n", line.label(), line.Current)
+			case ChangeRemoved:
+				fmt.Fprintf(&sb, "    %s removed: %q\n", line.label(), line.Previous)
+			default:
+				fmt.Fprintf(&sb, "    %s: %q -> %q\n", line.label(), line.Previous, line.Current)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// DiffStylesheet is the stylesheet embedded by ScreenplayDiff's ToHTML
+const DiffStylesheet = `.screenplay-diff {
+  font-family: "Courier Prime", "Courier Screenplay", "Courier New", Courier, monospace;
+  font-size: 12pt;
+  max-width: 80ch;
+  margin: 0 auto;
+}
+.screenplay-diff .scene { margin: 1em 0; padding: 0.5em 1ch; border-left: 4px solid #999; }
+.screenplay-diff .scene h2 { font-size: 1em; margin: 0; }
+.screenplay-diff .scene ul { list-style: none; margin: 0.5em 0 0 0; padding: 0; }
+.screenplay-diff .scene li { margin: 0.25em 0; }
+.screenplay-diff .added { border-color: #2a2; }
+.screenplay-diff .removed { border-color: #c22; }
+.screenplay-diff .moved { border-color: #26c; }
+.screenplay-diff .modified { border-color: #c90; }
+.screenplay-diff .character { font-weight: bold; }
+.screenplay-diff del { background: #fdd; }
+.screenplay-diff ins { background: #dfd; text-decoration: none; }
+`
+
+// ToHTMLFragment renders the changes as HTML, each changed scene is a
+// <section> with classes for its change (e.g. "scene moved") holding a
+// list of its changed speeches and action. Removed text is in <del> and
+// added text in <ins>.
+func (diff *ScreenplayDiff) ToHTMLFragment() string {
+	var sb strings.Builder
+	sb.WriteString("<div class=\"screenplay-diff\">\n")
+	if len(diff.Scenes) == 0 {
+		sb.WriteString("<p>No scenes changed</p>\n")
+	}
+	for _, change := range diff.Scenes {
+		fmt.Fprintf(&sb, "<section class=\"scene %s\">\n<h2>%s</h2>\n", change.Change, html.EscapeString(change.summary()))
+		lines := append(append([]*LineChange{}, change.Dialogue...), change.Action...)
+		if change.PreviousHeading == "" && len(lines) == 0 {
+			sb.WriteString("</section>\n")
+			continue
+		}
+		sb.WriteString("<ul>\n")
+		if change.PreviousHeading != "" {
+			fmt.Fprintf(&sb, "<li class=\"heading modified\">heading <del>%s</del> <ins>%s</ins></li>\n",
+				html.EscapeString(change.PreviousHeading), html.EscapeString(change.Heading))
+		}
+		for _, line := range lines {
+			kind, label := "dialogue", "<span class=\"character\">"+html.EscapeString(line.Character)+"</span>"
+			if line.Character == "" {
+				kind, label = "action", "action"
+			}
+			fmt.Fprintf(&sb, "<li class=\"%s %s\">%s", kind, line.Change, label)
+			if line.Previous != "" {
+				fmt.Fprintf(&sb, " <del>%s</del>", html.EscapeString(line.Previous))
+			}
+			if line.Current != "" {
+				fmt.Fprintf(&sb, " <ins>%s</ins>", html.EscapeString(line.Current))
+			}
+			sb.WriteString("</li>\n")
+		}
+		sb.WriteString("</ul>\n</section>\n")
+	}
+	sb.WriteString("</div>\n")
+	return sb.String()
+}
+
+// ToHTML renders the changes as a complete HTML page with DiffStylesheet
+// embedded (see ToHTMLFragment)
+func (diff *ScreenplayDiff) ToHTML() string {
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Screenplay changes</title>
+<style>
+%s</style>
+</head>
+<body>
+%s</body>
+</html>
+`, DiffStylesheet, diff.ToHTMLFragment())
+}
@@ -0,0 +1,133 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// scriptDocument returns a document with a paragraph for each of lines,
+// a line is a style name and the paragraph's text separated by ": "
+func scriptDocument(lines ...string) *OpenScreenplay {
+	document := NewOpenScreenplay20()
+	document.Settings = new(Settings)
+	document.Paragraphs = new(Paragraphs)
+	for _, line := range lines {
+		style, text, _ := strings.Cut(line, ": ")
+		document.Paragraphs.Para = append(document.Paragraphs.Para,
+			&Para{Style: &Style{BaseStyleName: style}, Text: []*Text{{InnerText: text}}})
+	}
+	return document
+}
+
+func TestDiff(t *testing.T) {
+	kitchen := []string{
+		"Scene Heading: INT. KITCHEN - DAY",
+		"Action: Ann makes coffee.",
+		"Character: Ann",
+		"Dialogue: Morning.",
+		"Character: Bob",
+		"Dialogue: Coffee?",
+	}
+	garden := []string{"Scene Heading: EXT. GARDEN - DAY", "Action: Birds sing in the tall trees by the old wall."}
+	office := []string{"Scene Heading: INT. OFFICE - NIGHT", "Action: Bob types a long report about the budget for next year."}
+	street := []string{"Scene Heading: EXT. STREET - NIGHT", "Action: Rain falls on the empty street."}
+	park := []string{"Scene Heading: EXT. PARK - NIGHT", "Action: A dog barks."}
+	roof := []string{"Scene Heading: EXT. ROOF - NIGHT", "Action: Ann looks at the stars."}
+	var lines []string
+	for _, scene := range [][]string{kitchen, garden, office, street, park, roof} {
+		lines = append(lines, scene...)
+	}
+	previous := scriptDocument(lines...)
+
+	lines = []string{
+		"Scene Heading: INT. KITCHEN - DAY",
+		"Action: Ann makes tea.",
+		"Character: Ann",
+		"Parenthetical: smiling",
+		"Dialogue: Good morning.",
+		"Character: Bob",
+		"Dialogue: Coffee?",
+		"Character: Ann (V.O.)",
+		"Dialogue: Later.",
+	}
+	lines = append(append(append(lines, street...), garden...), "Scene Heading: INT. OFFICE - LATE NIGHT", office[1])
+	lines = append(append(lines, "Scene Heading: INT. CAR - NIGHT", "Action: Ann drives."), park...)
+	current := scriptDocument(lines...)
+
+	diff, err := Diff(previous, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `Modified scene 1: INT. KITCHEN - DAY
+    ANN: "Morning." -> "(smiling) Good morning."
+    ANN added: "Later."
+    action: "Ann makes coffee." -> "Ann makes tea."
+Moved scene 2 (was scene 4): EXT. STREET - NIGHT
+Modified scene 4 (was scene 3): INT. OFFICE - LATE NIGHT
+    heading was: INT. OFFICE - NIGHT
+Added scene 5: INT. CAR - NIGHT
+Removed scene 6: EXT. ROOF - NIGHT
+`
+	if got := diff.String(); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+	if diff.Unchanged != 2 {
+		t.Errorf("expected two unchanged scenes, got %d", diff.Unchanged)
+	}
+	src := diff.ToHTML()
+	for _, s := range []string{
+		`<section class="scene moved">`,
+		`<li class="dialogue modified"><span class="character">ANN</span> <del>Morning.</del> <ins>(smiling) Good morning.</ins></li>`,
+		`<li class="heading modified">heading <del>INT. OFFICE - NIGHT</del> <ins>INT. OFFICE - LATE NIGHT</ins></li>`,
+	} {
+		if !strings.Contains(src, s) {
+			t.Errorf("expected %s in\n%s", s, src)
+		}
+	}
+
+	// A draft compared with itself has no changes
+	document, err := ParseFile(filepath.Join("testdata", "OSF-2.0.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err = Diff(document, document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Scenes) != 0 || diff.Unchanged == 0 || diff.String() != "No scenes changed\n" {
+		t.Errorf("expected no changes, got %s", diff)
+	}
+	if _, err := Diff(nil, document); err == nil {
+		t.Errorf("expected an error without a previous draft")
+	}
+}
@@ -0,0 +1,362 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"strings"
+)
+
+// The document element writes its children back in the order they were
+// read (see OpenScreenplay.MarshalXML). Elements holding other known
+// elements (e.g. paragraphs, para and lists) do the same with
+// unmarshalOrdered and marshalOrdered so an element that isn't modeled is
+// written back between the siblings it was read between rather than
+// after them.
+
+// unmarshalOrdered decodes the element start into v recording the names
+// of its children in the order they were read when one of them isn't
+// modeled. v must not have an UnmarshalXML method of its own.
+func unmarshalOrdered(d *xml.Decoder, start xml.StartElement, v interface{}, order *[]string) error {
+	// The element is copied and decoded from the copy, AnyElement needs
+	// the XML source to keep an element's inner XML
+	buf := new(bytes.Buffer)
+	enc := xml.NewEncoder(buf)
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	names := []string{}
+	depth := 0
+	for depth >= 0 {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				names = append(names, t.Name.Local)
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+		if err := enc.EncodeToken(token); err != nil {
+			return err
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	if err := xml.NewDecoder(buf).Decode(v); err != nil {
+		return err
+	}
+	// Known children are written in a fixed order anyway, the order only
+	// matters with unknown ones among them
+	value := reflect.ValueOf(v).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		if value.Type().Field(i).Tag.Get("xml") == ",any" && value.Field(i).Len() > 0 {
+			*order = names
+		}
+	}
+	return nil
+}
+
+// marshalOrdered encodes v with its children in order, the names of the
+// children as they were read. Children without a place in order (e.g.
+// paragraphs added since) are written after them. v is a pointer to a
+// struct whose child elements are pointers or slices and must not have a
+// MarshalXML method of its own.
+func marshalOrdered(e *xml.Encoder, v interface{}, order []string) error {
+	if len(order) == 0 {
+		return e.Encode(v)
+	}
+	value := reflect.ValueOf(v).Elem()
+	// The start tag is written from a copy of v without its children
+	empty := reflect.New(value.Type()).Elem()
+	empty.Set(value)
+	names, children := []string{}, []interface{}{}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		tag := field.Tag.Get("xml")
+		name, flags, _ := strings.Cut(tag, ",")
+		if !field.IsExported() || field.Name == "XMLName" || tag == "-" || strings.Contains(flags, "attr") {
+			continue
+		}
+		switch f := value.Field(i); f.Kind() {
+		case reflect.Ptr:
+			if !f.IsNil() {
+				names, children = append(names, name), append(children, f.Interface())
+			}
+		case reflect.Slice:
+			for j := 0; j < f.Len(); j++ {
+				child := f.Index(j).Interface()
+				if elem, ok := child.(*AnyElement); ok {
+					names = append(names, elem.XMLName.Local)
+				} else {
+					names = append(names, name)
+				}
+				children = append(children, child)
+			}
+		default:
+			return e.Encode(v)
+		}
+		empty.Field(i).Set(reflect.Zero(field.Type))
+	}
+	src, err := xml.Marshal(empty.Addr().Interface())
+	if err != nil {
+		return err
+	}
+	token, err := xml.NewDecoder(bytes.NewReader(src)).Token()
+	if err != nil {
+		return err
+	}
+	start, ok := token.(xml.StartElement)
+	if !ok {
+		return e.Encode(v)
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	written := make([]bool, len(children))
+	for _, name := range order {
+		for i := range children {
+			if !written[i] && names[i] == name {
+				written[i] = true
+				if err := e.Encode(children[i]); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	for i := range children {
+		if !written[i] {
+			if err := e.Encode(children[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML decodes Styles recording the order of its children.
+func (styles *Styles) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Styles
+	return unmarshalOrdered(d, start, (*plain)(styles), &styles.elementOrder)
+}
+
+// MarshalXML encodes Styles with its children in the order they were read.
+func (styles *Styles) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Styles
+	return marshalOrdered(e, (*plain)(styles), styles.elementOrder)
+}
+
+// UnmarshalXML decodes Paragraphs recording the order of its children.
+func (paragraphs *Paragraphs) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Paragraphs
+	return unmarshalOrdered(d, start, (*plain)(paragraphs), &paragraphs.elementOrder)
+}
+
+// MarshalXML encodes Paragraphs with its children in the order they were read.
+func (paragraphs *Paragraphs) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Paragraphs
+	return marshalOrdered(e, (*plain)(paragraphs), paragraphs.elementOrder)
+}
+
+// UnmarshalXML decodes Para recording the order of its children.
+func (para *Para) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Para
+	return unmarshalOrdered(d, start, (*plain)(para), &para.elementOrder)
+}
+
+// MarshalXML encodes Para with its children in the order they were read.
+func (para *Para) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Para
+	return marshalOrdered(e, (*plain)(para), para.elementOrder)
+}
+
+// UnmarshalXML decodes Marks recording the order of its children.
+func (marks *Marks) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Marks
+	return unmarshalOrdered(d, start, (*plain)(marks), &marks.elementOrder)
+}
+
+// MarshalXML encodes Marks with its children in the order they were read.
+func (marks *Marks) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Marks
+	return marshalOrdered(e, (*plain)(marks), marks.elementOrder)
+}
+
+// UnmarshalXML decodes TitlePage recording the order of its children.
+func (page *TitlePage) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain TitlePage
+	return unmarshalOrdered(d, start, (*plain)(page), &page.elementOrder)
+}
+
+// MarshalXML encodes TitlePage with its children in the order they were read.
+func (page *TitlePage) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain TitlePage
+	return marshalOrdered(e, (*plain)(page), page.elementOrder)
+}
+
+// UnmarshalXML decodes Spelling recording the order of its children.
+func (spelling *Spelling) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Spelling
+	return unmarshalOrdered(d, start, (*plain)(spelling), &spelling.elementOrder)
+}
+
+// MarshalXML encodes Spelling with its children in the order they were read.
+func (spelling *Spelling) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Spelling
+	return marshalOrdered(e, (*plain)(spelling), spelling.elementOrder)
+}
+
+// UnmarshalXML decodes UserDictionary recording the order of its children.
+func (dictionary *UserDictionary) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain UserDictionary
+	return unmarshalOrdered(d, start, (*plain)(dictionary), &dictionary.elementOrder)
+}
+
+// MarshalXML encodes UserDictionary with its children in the order they were read.
+func (dictionary *UserDictionary) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain UserDictionary
+	return marshalOrdered(e, (*plain)(dictionary), dictionary.elementOrder)
+}
+
+// UnmarshalXML decodes Lists recording the order of its children.
+func (lists *Lists) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Lists
+	return unmarshalOrdered(d, start, (*plain)(lists), &lists.elementOrder)
+}
+
+// MarshalXML encodes Lists with its children in the order they were read.
+func (lists *Lists) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Lists
+	return marshalOrdered(e, (*plain)(lists), lists.elementOrder)
+}
+
+// UnmarshalXML decodes Characters recording the order of its children.
+func (characters *Characters) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Characters
+	return unmarshalOrdered(d, start, (*plain)(characters), &characters.elementOrder)
+}
+
+// MarshalXML encodes Characters with its children in the order they were read.
+func (characters *Characters) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Characters
+	return marshalOrdered(e, (*plain)(characters), characters.elementOrder)
+}
+
+// UnmarshalXML decodes Locations recording the order of its children.
+func (locations *Locations) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Locations
+	return unmarshalOrdered(d, start, (*plain)(locations), &locations.elementOrder)
+}
+
+// MarshalXML encodes Locations with its children in the order they were read.
+func (locations *Locations) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Locations
+	return marshalOrdered(e, (*plain)(locations), locations.elementOrder)
+}
+
+// UnmarshalXML decodes SceneIntros recording the order of its children.
+func (intros *SceneIntros) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain SceneIntros
+	return unmarshalOrdered(d, start, (*plain)(intros), &intros.elementOrder)
+}
+
+// MarshalXML encodes SceneIntros with its children in the order they were read.
+func (intros *SceneIntros) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain SceneIntros
+	return marshalOrdered(e, (*plain)(intros), intros.elementOrder)
+}
+
+// UnmarshalXML decodes SceneTimes recording the order of its children.
+func (times *SceneTimes) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain SceneTimes
+	return unmarshalOrdered(d, start, (*plain)(times), &times.elementOrder)
+}
+
+// MarshalXML encodes SceneTimes with its children in the order they were read.
+func (times *SceneTimes) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain SceneTimes
+	return marshalOrdered(e, (*plain)(times), times.elementOrder)
+}
+
+// UnmarshalXML decodes Extensions recording the order of its children.
+func (extensions *Extensions) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Extensions
+	return unmarshalOrdered(d, start, (*plain)(extensions), &extensions.elementOrder)
+}
+
+// MarshalXML encodes Extensions with its children in the order they were read.
+func (extensions *Extensions) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Extensions
+	return marshalOrdered(e, (*plain)(extensions), extensions.elementOrder)
+}
+
+// UnmarshalXML decodes Transitions recording the order of its children.
+func (transitions *Transitions) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Transitions
+	return unmarshalOrdered(d, start, (*plain)(transitions), &transitions.elementOrder)
+}
+
+// MarshalXML encodes Transitions with its children in the order they were read.
+func (transitions *Transitions) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Transitions
+	return marshalOrdered(e, (*plain)(transitions), transitions.elementOrder)
+}
+
+// UnmarshalXML decodes RevisionColors recording the order of its children.
+func (colors *RevisionColors) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain RevisionColors
+	return unmarshalOrdered(d, start, (*plain)(colors), &colors.elementOrder)
+}
+
+// MarshalXML encodes RevisionColors with its children in the order they were read.
+func (colors *RevisionColors) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain RevisionColors
+	return marshalOrdered(e, (*plain)(colors), colors.elementOrder)
+}
+
+// UnmarshalXML decodes TagCategories recording the order of its children.
+func (categories *TagCategories) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain TagCategories
+	return unmarshalOrdered(d, start, (*plain)(categories), &categories.elementOrder)
+}
+
+// MarshalXML encodes TagCategories with its children in the order they were read.
+func (categories *TagCategories) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain TagCategories
+	return marshalOrdered(e, (*plain)(categories), categories.elementOrder)
+}
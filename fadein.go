@@ -0,0 +1,216 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	// FadeInDocumentName is the archive member holding the OSF document
+	FadeInDocumentName = "document.xml"
+)
+
+// FadeInArchive holds the contents of a Fade In (.fadein) file. A Fade In
+// file is a zip archive with the screenplay stored as OSF in document.xml,
+// other members (e.g. images, metadata) are kept as is so they can be
+// written back out.
+type FadeInArchive struct {
+	members []*fadeInMember
+}
+
+// fadeInMember is a single file stored in a FadeInArchive
+type fadeInMember struct {
+	header zip.FileHeader
+	data   []byte
+}
+
+// NewFadeInArchive returns an empty Fade In archive
+func NewFadeInArchive() *FadeInArchive {
+	return new(FadeInArchive)
+}
+
+// ReadFadeIn reads a Fade In archive from r
+func ReadFadeIn(r io.ReaderAt, size int64) (*FadeInArchive, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	archive := NewFadeInArchive()
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		archive.members = append(archive.members, &fadeInMember{
+			header: f.FileHeader,
+			data:   data,
+		})
+	}
+	return archive, nil
+}
+
+// OpenFadeIn reads the Fade In archive named fname
+func OpenFadeIn(fname string) (*FadeInArchive, error) {
+	src, err := os.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := ReadFadeIn(bytes.NewReader(src), int64(len(src)))
+	if err != nil {
+		return nil, fmt.Errorf("%s, %s", fname, err)
+	}
+	return archive, nil
+}
+
+// member returns the named member or nil if it does not exist
+func (archive *FadeInArchive) member(name string) *fadeInMember {
+	for _, m := range archive.members {
+		if m.header.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// Members returns the names of the files in the archive
+func (archive *FadeInArchive) Members() []string {
+	names := []string{}
+	for _, m := range archive.members {
+		names = append(names, m.header.Name)
+	}
+	return names
+}
+
+// ReadMember returns the contents of a file in the archive
+func (archive *FadeInArchive) ReadMember(name string) ([]byte, error) {
+	if m := archive.member(name); m != nil {
+		return m.data, nil
+	}
+	return nil, fmt.Errorf("%s not found in Fade In archive", name)
+}
+
+// WriteMember adds or replaces a file in the archive
+func (archive *FadeInArchive) WriteMember(name string, data []byte) {
+	if m := archive.member(name); m != nil {
+		m.data = data
+		m.header.Modified = time.Now()
+		return
+	}
+	archive.members = append(archive.members, &fadeInMember{
+		header: zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		},
+		data: data,
+	})
+}
+
+// Document parses document.xml and returns the screenplay
+func (archive *FadeInArchive) Document() (*OpenScreenplay, error) {
+	m := archive.member(FadeInDocumentName)
+	if m == nil {
+		return nil, fmt.Errorf("Fade In archive is missing %s", FadeInDocumentName)
+	}
+	return Parse(m.data)
+}
+
+// SetDocument replaces document.xml with the XML for document
+func (archive *FadeInArchive) SetDocument(document *OpenScreenplay) error {
+	src, err := document.ToXML()
+	if err != nil {
+		return err
+	}
+	archive.WriteMember(FadeInDocumentName, append([]byte(DocString+"\n"), src...))
+	return nil
+}
+
+// Write writes the archive as a zip file to w
+func (archive *FadeInArchive) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	for _, m := range archive.members {
+		header := &zip.FileHeader{
+			Name:          m.header.Name,
+			Comment:       m.header.Comment,
+			Method:        m.header.Method,
+			Modified:      m.header.Modified,
+			ExternalAttrs: m.header.ExternalAttrs,
+		}
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(m.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// Save writes the archive to the file named fname
+func (archive *FadeInArchive) Save(fname string) error {
+	buf := new(bytes.Buffer)
+	if err := archive.Write(buf); err != nil {
+		return err
+	}
+	return os.WriteFile(fname, buf.Bytes(), 0664)
+}
+
+// WriteFadeIn saves document as the Fade In file fname. If fname already
+// exists only document.xml is replaced, the other members are preserved.
+func WriteFadeIn(fname string, document *OpenScreenplay) error {
+	var (
+		archive *FadeInArchive
+		err     error
+	)
+	if _, err = os.Stat(fname); err == nil {
+		archive, err = OpenFadeIn(fname)
+		if err != nil {
+			return err
+		}
+	} else {
+		archive = NewFadeInArchive()
+	}
+	if err := archive.SetDocument(document); err != nil {
+		return err
+	}
+	return archive.Save(fname)
+}
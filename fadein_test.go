@@ -0,0 +1,108 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFadeInArchive(t *testing.T) {
+	archive, err := OpenFadeIn(filepath.Join("testdata", "sample-01.fadein"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	document, err := archive.Document()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if document.Paragraphs == nil || len(document.Paragraphs.Para) == 0 {
+		t.Fatalf("expected paragraphs in sample-01.fadein")
+	}
+	// Add an asset the way a Fade In project carries images, then write
+	// a changed document and make sure the asset survives.
+	asset := []byte("\x89PNG\r\n\x1a\nnot really an image")
+	archive.WriteMember("images/poster.png", asset)
+	fname := filepath.Join(t.TempDir(), "sample-01.fadein")
+	if err := archive.Save(fname); err != nil {
+		t.Fatal(err)
+	}
+	document.Paragraphs.Para[0].Text[0].InnerText = "Changed"
+	if err := WriteFadeIn(fname, document); err != nil {
+		t.Fatal(err)
+	}
+	archive, err = OpenFadeIn(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := archive.Members(); len(names) != 2 {
+		t.Errorf("expected 2 members, got %q", names)
+	}
+	src, err := archive.ReadMember("images/poster.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, asset) {
+		t.Errorf("asset was not preserved, got %q", src)
+	}
+	document, err = ParseFile(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := document.Paragraphs.Para[0].Text[0].InnerText; got != "Changed" {
+		t.Errorf("expected changed document.xml, got %q", got)
+	}
+
+	// A new file gets just document.xml
+	fname = filepath.Join(t.TempDir(), "new.fadein")
+	if err := WriteFadeIn(fname, document); err != nil {
+		t.Fatal(err)
+	}
+	archive, err = OpenFadeIn(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := archive.Members(); len(names) != 1 || names[0] != FadeInDocumentName {
+		t.Errorf("expected only %s, got %q", FadeInDocumentName, names)
+	}
+
+	// A .fadein without document.xml is an error
+	archive = NewFadeInArchive()
+	archive.WriteMember("images/poster.png", asset)
+	fname = filepath.Join(t.TempDir(), "empty.fadein")
+	if err := archive.Save(fname); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseFile(fname); err == nil || !strings.Contains(err.Error(), FadeInDocumentName) {
+		t.Errorf("expected missing %s error, got %v", FadeInDocumentName, err)
+	}
+}
@@ -0,0 +1,1006 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+// Final Draft (.fdx) documents are XML with a FinalDraft root element.
+// The structs below model the parts of FDX that have an equivalent in
+// OSF, everything else in an FDX file is ignored.
+
+type fdxDocument struct {
+	XMLName            xml.Name               `xml:"FinalDraft"`
+	DocumentType       string                 `xml:"DocumentType,attr,omitempty"`
+	Template           string                 `xml:"Template,attr,omitempty"`
+	Version            string                 `xml:"Version,attr,omitempty"`
+	Content            *fdxContent            `xml:"Content,omitempty"`
+	HeaderAndFooter    *fdxHeaderAndFooter    `xml:"HeaderAndFooter,omitempty"`
+	PageLayout         *fdxPageLayout         `xml:"PageLayout,omitempty"`
+	ElementSettings    []*fdxElementSettings  `xml:"ElementSettings,omitempty"`
+	TitlePage          *fdxTitlePage          `xml:"TitlePage,omitempty"`
+	SmartType          *fdxSmartType          `xml:"SmartType,omitempty"`
+	MoresAndContinueds *fdxMoresAndContinueds `xml:"MoresAndContinueds,omitempty"`
+	Revisions          *fdxRevisions          `xml:"Revisions,omitempty"`
+}
+
+type fdxContent struct {
+	Paragraph []*fdxParagraph `xml:"Paragraph"`
+}
+
+type fdxParagraph struct {
+	Type            string              `xml:"Type,attr,omitempty"`
+	Number          string              `xml:"Number,attr,omitempty"`
+	Alignment       string              `xml:"Alignment,attr,omitempty"`
+	FirstIndent     string              `xml:"FirstIndent,attr,omitempty"`
+	Leading         string              `xml:"Leading,attr,omitempty"`
+	LeftIndent      string              `xml:"LeftIndent,attr,omitempty"`
+	RightIndent     string              `xml:"RightIndent,attr,omitempty"`
+	SpaceBefore     string              `xml:"SpaceBefore,attr,omitempty"`
+	Spacing         string              `xml:"Spacing,attr,omitempty"`
+	StartsNewPage   string              `xml:"StartsNewPage,attr,omitempty"`
+	SceneProperties *fdxSceneProperties `xml:"SceneProperties,omitempty"`
+	DualDialogue    *fdxContent         `xml:"DualDialogue,omitempty"`
+	Text            []*fdxText          `xml:"Text"`
+}
+
+type fdxSceneProperties struct {
+	Length  string      `xml:"Length,attr,omitempty"`
+	Page    string      `xml:"Page,attr,omitempty"`
+	Title   string      `xml:"Title,attr"`
+	Color   string      `xml:"Color,attr,omitempty"`
+	Summary *fdxContent `xml:"Summary,omitempty"`
+}
+
+type fdxText struct {
+	AdornmentStyle string `xml:"AdornmentStyle,attr,omitempty"`
+	Background     string `xml:"Background,attr,omitempty"`
+	Color          string `xml:"Color,attr,omitempty"`
+	Font           string `xml:"Font,attr,omitempty"`
+	RevisionID     string `xml:"RevisionID,attr,omitempty"`
+	Size           string `xml:"Size,attr,omitempty"`
+	Style          string `xml:"Style,attr,omitempty"`
+	InnerText      string `xml:",chardata"`
+}
+
+type fdxHeaderAndFooter struct {
+	FooterFirstPage string `xml:"FooterFirstPage,attr,omitempty"`
+	FooterVisible   string `xml:"FooterVisible,attr,omitempty"`
+	HeaderFirstPage string `xml:"HeaderFirstPage,attr,omitempty"`
+	HeaderVisible   string `xml:"HeaderVisible,attr,omitempty"`
+	StartingPage    string `xml:"StartingPage,attr,omitempty"`
+}
+
+type fdxPageLayout struct {
+	BottomMargin                      string       `xml:"BottomMargin,attr,omitempty"`
+	BreakDialogueAndActionAtSentences string       `xml:"BreakDialogueAndActionAtSentences,attr,omitempty"`
+	FooterMargin                      string       `xml:"FooterMargin,attr,omitempty"`
+	HeaderMargin                      string       `xml:"HeaderMargin,attr,omitempty"`
+	TopMargin                         string       `xml:"TopMargin,attr,omitempty"`
+	PageSize                          *fdxPageSize `xml:"PageSize,omitempty"`
+}
+
+type fdxPageSize struct {
+	Height string `xml:"Height,attr,omitempty"`
+	Width  string `xml:"Width,attr,omitempty"`
+}
+
+type fdxElementSettings struct {
+	Type          string        `xml:"Type,attr"`
+	FontSpec      *fdxText      `xml:"FontSpec,omitempty"`
+	ParagraphSpec *fdxParagraph `xml:"ParagraphSpec,omitempty"`
+	Behavior      *fdxBehavior  `xml:"Behavior,omitempty"`
+}
+
+type fdxBehavior struct {
+	PaginateAs string `xml:"PaginateAs,attr,omitempty"`
+	ReturnKey  string `xml:"ReturnKey,attr,omitempty"`
+	Shortcut   string `xml:"Shortcut,attr,omitempty"`
+}
+
+type fdxTitlePage struct {
+	Content *fdxContent `xml:"Content,omitempty"`
+}
+
+type fdxSmartType struct {
+	Characters  []string        `xml:"Characters>Character"`
+	Extensions  []string        `xml:"Extensions>Extension"`
+	SceneIntros *fdxSceneIntros `xml:"SceneIntros,omitempty"`
+	Locations   []string        `xml:"Locations>Location"`
+	TimesOfDay  *fdxTimesOfDay  `xml:"TimesOfDay,omitempty"`
+	Transitions []string        `xml:"Transitions>Transition"`
+}
+
+type fdxSceneIntros struct {
+	Separator  string   `xml:"Separator,attr"`
+	SceneIntro []string `xml:"SceneIntro"`
+}
+
+type fdxTimesOfDay struct {
+	Separator string   `xml:"Separator,attr"`
+	TimeOfDay []string `xml:"TimeOfDay"`
+}
+
+type fdxMoresAndContinueds struct {
+	DialogueBreaks *fdxDialogueBreaks `xml:"DialogueBreaks,omitempty"`
+	SceneBreaks    *fdxSceneBreaks    `xml:"SceneBreaks,omitempty"`
+}
+
+type fdxDialogueBreaks struct {
+	AutomaticCharacterContinueds string `xml:"AutomaticCharacterContinueds,attr,omitempty"`
+	BottomOfPage                 string `xml:"BottomOfPage,attr,omitempty"`
+	DialogueBottom               string `xml:"DialogueBottom,attr,omitempty"`
+	DialogueTop                  string `xml:"DialogueTop,attr,omitempty"`
+	TopOfNext                    string `xml:"TopOfNext,attr,omitempty"`
+}
+
+type fdxSceneBreaks struct {
+	ContinuedNumber   string `xml:"ContinuedNumber,attr,omitempty"`
+	SceneBottom       string `xml:"SceneBottom,attr,omitempty"`
+	SceneBottomOfPage string `xml:"SceneBottomOfPage,attr,omitempty"`
+	SceneTop          string `xml:"SceneTop,attr,omitempty"`
+	SceneTopOfNext    string `xml:"SceneTopOfNext,attr,omitempty"`
+}
+
+type fdxRevisions struct {
+	ActiveSet      string         `xml:"ActiveSet,attr,omitempty"`
+	RevisionMode   string         `xml:"RevisionMode,attr,omitempty"`
+	RevisionsShown string         `xml:"RevisionsShown,attr,omitempty"`
+	Revision       []*fdxRevision `xml:"Revision"`
+}
+
+type fdxRevision struct {
+	Color        string `xml:"Color,attr,omitempty"`
+	FullRevision string `xml:"FullRevision,attr,omitempty"`
+	ID           string `xml:"ID,attr"`
+	Mark         string `xml:"Mark,attr,omitempty"`
+	Name         string `xml:"Name,attr,omitempty"`
+	PageColor    string `xml:"PageColor,attr,omitempty"`
+	Style        string `xml:"Style,attr"`
+}
+
+const (
+	// fdxGeneralType is Final Draft's name for OSF's Normal Text
+	fdxGeneralType = "General"
+)
+
+// fdxRevisionColors are the text and page colors of Final Draft's
+// revision sets by color name, used for revision colors that didn't come
+// from an FDX file
+var fdxRevisionColors = map[string][2]string{
+	"blue":      {"#00000000FFFF", "#C6C6EDEDFEFE"},
+	"pink":      {"#FFFF0000FFFF", "#FDFDCCCCD4D4"},
+	"yellow":    {"#A0A0A0A00000", "#FDFDFFFFABAB"},
+	"green":     {"#000080800000", "#D1D1FEFED0D0"},
+	"goldenrod": {"#CCCC7F7F3232", "#FAFACDCD3939"},
+	"buff":      {"#8E8E6B6B2323", "#FCFCEDED9D9D"},
+	"salmon":    {"#A7A742424242", "#F8F8AEAE8E8E"},
+	"cherry":    {"#D5D523236B6B", "#FBFBA4A4B4B4"},
+	"tan":       {"#DBDB93937070", "#FDFDF1F1C7C7"},
+}
+
+// fdxRevisionAttrs returns the attributes of an FDX revision set that
+// OSF has no place for, they are kept with the revision color (e.g.
+// fdx_color="#00000000FFFF") so ToFDX can write them back
+func fdxRevisionAttrs(r *fdxRevision) []xml.Attr {
+	attrs := []xml.Attr{}
+	for _, attr := range []xml.Attr{
+		{Name: xml.Name{Local: "fdx_color"}, Value: r.Color},
+		{Name: xml.Name{Local: "fdx_page_color"}, Value: r.PageColor},
+		{Name: xml.Name{Local: "fdx_mark"}, Value: r.Mark},
+		{Name: xml.Name{Local: "fdx_full_revision"}, Value: r.FullRevision},
+		{Name: xml.Name{Local: "fdx_style"}, Value: r.Style},
+	} {
+		if attr.Value != "" {
+			attrs = append(attrs, attr)
+		}
+	}
+	return attrs
+}
+
+// toFDXRevision converts a revision color to an FDX revision set. The
+// colors come from the color name and revised text is marked with "*"
+// unless the revision color has FDX attributes (e.g.
+// fdx_color="#00000000FFFF") kept by FromFDX.
+func (color *RevisionColor) toFDXRevision() *fdxRevision {
+	r := &fdxRevision{ID: color.Index, Name: color.Name, Mark: "*", FullRevision: "No"}
+	if colors, ok := fdxRevisionColors[strings.ToLower(color.ColorName)]; ok {
+		r.Color, r.PageColor = colors[0], colors[1]
+	}
+	for _, attr := range color.UnknownAttrs {
+		switch attr.Name.Local {
+		case "fdx_color":
+			r.Color = attr.Value
+		case "fdx_page_color":
+			r.PageColor = attr.Value
+		case "fdx_mark":
+			r.Mark = attr.Value
+		case "fdx_full_revision":
+			r.FullRevision = attr.Value
+		case "fdx_style":
+			r.Style = attr.Value
+		}
+	}
+	return r
+}
+
+// fdxYes converts an FDX Yes/No attribute to an OSF boolean attribute
+func fdxYes(s string) string {
+	return FormatBool(strings.EqualFold(s, "yes"))
+}
+
+// fdxStyleName maps an FDX paragraph type to an OSF style name
+func fdxStyleName(s string) string {
+	if s == fdxGeneralType || s == "" {
+		return GeneralType
+	}
+	return s
+}
+
+// fdxInches parses an FDX measurement in inches
+func fdxInches(s string) (Length, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, false
+	}
+	return LengthFromInches(f), true
+}
+
+// fdxPoints parses an FDX measurement in points
+func fdxPoints(s string) (Length, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, false
+	}
+	return LengthFromPoints(f), true
+}
+
+// fdxLines converts an FDX SpaceBefore in points into lines for spacebefore
+func fdxLines(s string) string {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return ""
+	}
+	return formatFloat(f / 12)
+}
+
+// fdxAlign maps an FDX Alignment to an OSF align attribute
+func fdxAlign(s string) string {
+	switch s {
+	case "", "Left":
+		return ""
+	case "Full":
+		return "justify"
+	}
+	return strings.ToLower(s)
+}
+
+// fdxToText converts an FDX Text element, e.g. Style="Bold+Underline", to OSF
+func fdxToText(t *fdxText) *Text {
+	text := new(Text)
+	text.InnerText = t.InnerText
+	for _, style := range strings.Split(t.Style, "+") {
+		switch style {
+		case "Bold":
+			text.Bold = BoldStyle
+		case "Italic":
+			text.Italic = ItalicStyle
+		case "Underline":
+			text.Underline = UnderlineStyle
+		case "Strikeout":
+			text.Strikethrough = StrikethroughStyle
+		case "AllCaps":
+			text.AllCaps = AllCapsStyle
+		}
+	}
+	if t.RevisionID != "" && t.RevisionID != "0" {
+		text.Revision = t.RevisionID
+	}
+	return text
+}
+
+// fdxInnerText returns the text of a paragraph without formatting
+func fdxInnerText(p *fdxParagraph) string {
+	parts := []string{}
+	for _, t := range p.Text {
+		parts = append(parts, t.InnerText)
+	}
+	return strings.Join(parts, "")
+}
+
+// fdxSynopsis combines a scene's title and summary
+func fdxSynopsis(properties *fdxSceneProperties) string {
+	parts := []string{}
+	if s := strings.TrimSpace(properties.Title); s != "" {
+		parts = append(parts, s)
+	}
+	if properties.Summary != nil {
+		for _, p := range properties.Summary.Paragraph {
+			if s := strings.TrimSpace(fdxInnerText(p)); s != "" {
+				parts = append(parts, s)
+			}
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// fdxToPara converts a FDX Paragraph into one or more OSF paras, a dual
+// dialogue block is flattened with its first character marked.
+func fdxToPara(p *fdxParagraph) []*Para {
+	if p.DualDialogue != nil {
+		paras := []*Para{}
+		marked := false
+		for _, child := range p.DualDialogue.Paragraph {
+			for _, para := range fdxToPara(child) {
+				if !marked && para.Style.BaseStyleName == CharacterType {
+					para.Style.DualDialogue = "1"
+					marked = true
+				}
+				paras = append(paras, para)
+			}
+		}
+		return paras
+	}
+	para := new(Para)
+	para.Style = new(Style)
+	para.Style.BaseStyleName = fdxStyleName(p.Type)
+	para.Style.Align = fdxAlign(p.Alignment)
+	if strings.EqualFold(p.StartsNewPage, "yes") {
+		para.Style.PageBreakBefore = "1"
+	}
+	para.SceneNumber = p.Number
+	if p.SceneProperties != nil {
+		para.PageNumber = p.SceneProperties.Page
+		para.Synopsis = fdxSynopsis(p.SceneProperties)
+		if para.Synopsis != "" && p.SceneProperties.Color != "" {
+			para.SynopsisColor = p.SceneProperties.Color
+		}
+	}
+	for _, t := range p.Text {
+		para.Text = append(para.Text, fdxToText(t))
+	}
+	if len(para.Text) == 0 {
+		para.Text = append(para.Text, new(Text))
+	}
+	// OSF parentheticals are stored without their parentheses
+	if para.Style.BaseStyleName == ParentheticalType {
+		first, last := para.Text[0], para.Text[len(para.Text)-1]
+		if strings.HasPrefix(first.InnerText, "(") && strings.HasSuffix(last.InnerText, ")") {
+			first.InnerText = strings.TrimPrefix(first.InnerText, "(")
+			last.InnerText = strings.TrimSuffix(last.InnerText, ")")
+		}
+	}
+	return []*Para{para}
+}
+
+// fdxToStyle converts FDX ElementSettings into an OSF style. Indents in FDX
+// are measured from the left edge of the page, in OSF from the margins
+// which are taken from the General element.
+func fdxToStyle(settings *fdxElementSettings, index int, left, right Length) *Style {
+	style := new(Style)
+	style.Name = fdxStyleName(settings.Type)
+	style.Label = style.Name
+	switch style.Name {
+	case GeneralType, SceneHeadingType, ActionType, CharacterType, ParentheticalType, DialogueType, TransitionType, ShotType, CastListType:
+		style.Builtin = "1"
+		style.BuiltinIndex = strconv.Itoa(index)
+	}
+	if style.Name != GeneralType {
+		style.BaseStyleName = GeneralType
+	}
+	if settings.Behavior != nil && settings.Behavior.ReturnKey != "" {
+		style.StyleEnter = fdxStyleName(settings.Behavior.ReturnKey)
+	}
+	if font := settings.FontSpec; font != nil {
+		style.Font = font.Font
+		style.Size = font.Size
+		text := fdxToText(font)
+		style.Bold = text.Bold
+		style.Italic = text.Italic
+		style.Underline = text.Underline
+		style.AllCaps = text.AllCaps
+	}
+	if spec := settings.ParagraphSpec; spec != nil {
+		style.Align = fdxAlign(spec.Alignment)
+		style.SpaceBefore = fdxLines(spec.SpaceBefore)
+		if spec.Spacing != "" && spec.Spacing != "1" {
+			style.LineSpacing = spec.Spacing
+		}
+		if indent, ok := fdxInches(spec.LeftIndent); ok && indent != left {
+			style.LeftIdent = (indent - left).String()
+		}
+		if indent, ok := fdxInches(spec.RightIndent); ok && indent != right {
+			style.RightIdent = (right - indent).String()
+		}
+		if strings.EqualFold(spec.StartsNewPage, "yes") {
+			style.PageBreakBefore = "1"
+		}
+	}
+	return style
+}
+
+// fdxTitlePageInfo fills in Info from the text of a title page. Final
+// Draft does not label title page paragraphs so the title is taken to be
+// the first centered paragraph and the author follows a "by" line.
+func fdxTitlePageInfo(info *Info, paras []*Para) {
+	afterBy := false
+	for _, para := range paras {
+		s := strings.TrimSpace(para.PlainText())
+		if s == "" {
+			continue
+		}
+		lower := strings.ToLower(s)
+		switch {
+		case info.Title == "" && para.Style != nil && para.Style.Align == "center":
+			info.Title = s
+			para.Bookmark = "Title"
+		case lower == "by" || strings.HasSuffix(lower, " by"):
+			afterBy = true
+			para.Bookmark = "Credits"
+		case afterBy && info.WrittenBy == "":
+			info.WrittenBy = s
+			para.Bookmark = "Author"
+		case info.Copyright == "" && (strings.HasPrefix(lower, "copyright") || strings.HasPrefix(lower, "(c)") || strings.HasPrefix(s, "©")):
+			info.Copyright = s
+			para.Bookmark = "Copyright"
+		}
+	}
+}
+
+// FromFDX converts a Final Draft (.fdx) XML document into an OpenScreenplay
+// document.
+func FromFDX(src []byte) (*OpenScreenplay, error) {
+	fdx := new(fdxDocument)
+	if err := xml.Unmarshal(src, &fdx); err != nil {
+		return nil, err
+	}
+	document := NewOpenScreenplay20()
+	document.Settings = new(Settings)
+	settings := document.Settings
+
+	// Page layout, FDX uses points for the top and bottom margins and
+	// inches for the page size, the left and right margins come from
+	// the General element's indents.
+	left, right := DefaultMarginLeft, LetterWidth-DefaultMarginRight
+	for _, elem := range fdx.ElementSettings {
+		if elem.Type == fdxGeneralType && elem.ParagraphSpec != nil {
+			if indent, ok := fdxInches(elem.ParagraphSpec.LeftIndent); ok {
+				left = indent
+			}
+			if indent, ok := fdxInches(elem.ParagraphSpec.RightIndent); ok {
+				right = indent
+			}
+		}
+	}
+	width, height := settings.PageSize()
+	top, bottom := DefaultMarginTop, DefaultMarginBottom
+	if layout := fdx.PageLayout; layout != nil {
+		if layout.PageSize != nil {
+			if l, ok := fdxInches(layout.PageSize.Width); ok {
+				width = l
+			}
+			if l, ok := fdxInches(layout.PageSize.Height); ok {
+				height = l
+			}
+		}
+		if l, ok := fdxPoints(layout.TopMargin); ok {
+			top = l
+		}
+		if l, ok := fdxPoints(layout.BottomMargin); ok {
+			bottom = l
+		}
+		if layout.BreakDialogueAndActionAtSentences != "" {
+			settings.BreakOnSentences = fdxYes(layout.BreakDialogueAndActionAtSentences)
+		}
+	}
+	settings.SetPageSize(width, height)
+	settings.SetMargins(top, bottom, left, width-right)
+
+	if hf := fdx.HeaderAndFooter; hf != nil && hf.StartingPage != "" {
+		settings.PageNumberStart = hf.StartingPage
+	}
+	if mc := fdx.MoresAndContinueds; mc != nil {
+		if db := mc.DialogueBreaks; db != nil {
+			settings.DialogueContinues = fdxYes(db.AutomaticCharacterContinueds)
+			settings.MoreText = db.DialogueBottom
+			settings.ContText = db.DialogueTop
+		}
+		if sb := mc.SceneBreaks; sb != nil {
+			settings.ContinuedText = strings.TrimSuffix(sb.SceneTop, ":")
+			settings.NumberContinued = fdxYes(sb.ContinuedNumber)
+		}
+	}
+
+	// Styles
+	if len(fdx.ElementSettings) > 0 {
+		document.Styles = new(Styles)
+		for i, elem := range fdx.ElementSettings {
+			document.Styles.Style = append(document.Styles.Style, fdxToStyle(elem, i, left, right))
+		}
+	}
+
+	// Paragraphs
+	document.Paragraphs = new(Paragraphs)
+	if fdx.Content != nil {
+		for _, p := range fdx.Content.Paragraph {
+			document.Paragraphs.Para = append(document.Paragraphs.Para, fdxToPara(p)...)
+		}
+		for _, para := range document.Paragraphs.Para {
+			if para.SceneNumber != "" {
+				settings.SceneNumbering = FormatBool(true)
+				break
+			}
+		}
+	}
+
+	// Title page
+	if fdx.TitlePage != nil && fdx.TitlePage.Content != nil {
+		document.TitlePage = new(TitlePage)
+		for _, p := range fdx.TitlePage.Content.Paragraph {
+			document.TitlePage.Para = append(document.TitlePage.Para, fdxToPara(p)...)
+		}
+		fdxTitlePageInfo(document.Info, document.TitlePage.Para)
+	}
+
+	// Lists
+	lists := new(Lists)
+	hasLists := false
+	if st := fdx.SmartType; st != nil {
+		if len(st.Characters) > 0 {
+			lists.Characters = new(Characters)
+			for _, name := range st.Characters {
+				lists.Characters.Character = append(lists.Characters.Character, &Character{Name: name})
+			}
+		}
+		if len(st.Locations) > 0 {
+			lists.Locations = new(Locations)
+			for _, name := range st.Locations {
+				lists.Locations.Location = append(lists.Locations.Location, &Location{Name: name})
+			}
+		}
+		if st.SceneIntros != nil && len(st.SceneIntros.SceneIntro) > 0 {
+			lists.SceneIntros = new(SceneIntros)
+			for _, name := range st.SceneIntros.SceneIntro {
+				lists.SceneIntros.SceneIntro = append(lists.SceneIntros.SceneIntro, &SceneIntro{Name: name})
+			}
+		}
+		if st.TimesOfDay != nil {
+			if st.TimesOfDay.Separator != "" {
+				settings.SceneTimeSeparator = st.TimesOfDay.Separator
+			}
+			if len(st.TimesOfDay.TimeOfDay) > 0 {
+				lists.SceneTimes = new(SceneTimes)
+				for _, name := range st.TimesOfDay.TimeOfDay {
+					lists.SceneTimes.SceneTime = append(lists.SceneTimes.SceneTime, &SceneTime{Name: name})
+				}
+			}
+		}
+		if len(st.Extensions) > 0 {
+			lists.Extensions = new(Extensions)
+			for _, name := range st.Extensions {
+				lists.Extensions.Extension = append(lists.Extensions.Extension, &Extension{Name: name})
+			}
+		}
+		if len(st.Transitions) > 0 {
+			lists.Transitions = new(Transitions)
+			for _, name := range st.Transitions {
+				lists.Transitions.Transition = append(lists.Transitions.Transition, &Transition{Name: name})
+			}
+		}
+		hasLists = true
+	}
+
+	// Revisions, FDX revision IDs are used as the OSF revision index
+	if rev := fdx.Revisions; rev != nil {
+		if rev.ActiveSet != "" {
+			settings.Revision = rev.ActiveSet
+		}
+		if rev.RevisionMode != "" {
+			settings.ShowRevisions = fdxYes(rev.RevisionMode)
+		}
+		if rev.RevisionsShown == "All" {
+			settings.ShowAllRevisions = FormatBool(true)
+		}
+		if len(rev.Revision) > 0 {
+			lists.RevisionColors = new(RevisionColors)
+			for _, r := range rev.Revision {
+				lists.RevisionColors.RevisionColor = append(lists.RevisionColors.RevisionColor, &RevisionColor{
+					Name:         r.Name,
+					Index:        r.ID,
+					ColorName:    r.Name,
+					ColorIndex:   r.ID,
+					UnknownAttrs: fdxRevisionAttrs(r),
+				})
+			}
+			hasLists = true
+		}
+	}
+	if hasLists {
+		document.Lists = lists
+	}
+	return document, nil
+}
+
+// fdxYesNo converts an OSF boolean attribute to an FDX Yes/No attribute
+func fdxYesNo(s string) string {
+	if ParseBool(s) {
+		return "Yes"
+	}
+	return "No"
+}
+
+// fdxTypeName maps an OSF style name to an FDX paragraph type
+func fdxTypeName(s string) string {
+	if s == GeneralType || s == "" {
+		return fdxGeneralType
+	}
+	return s
+}
+
+// fdxAlignment maps an OSF align attribute to an FDX Alignment
+func fdxAlignment(s string) string {
+	switch strings.ToLower(s) {
+	case "center":
+		return CenterAlignment
+	case "right":
+		return RightAlignment
+	case "justify":
+		return "Full"
+	case "left":
+		return LeftAlignment
+	}
+	return ""
+}
+
+// fdxFormatInches formats a length as an FDX measurement in inches
+func fdxFormatInches(l Length) string {
+	return strconv.FormatFloat(l.Inches(), 'f', 2, 64)
+}
+
+// fdxFromText converts an OSF text run into an FDX Text element
+func fdxFromText(text *Text) *fdxText {
+	t := new(fdxText)
+	t.InnerText = text.InnerText
+	styles := []string{}
+	if text.Bold == BoldStyle {
+		styles = append(styles, "Bold")
+	}
+	if text.Italic == ItalicStyle {
+		styles = append(styles, "Italic")
+	}
+	if text.Underline == UnderlineStyle {
+		styles = append(styles, "Underline")
+	}
+	if text.Strikethrough == StrikethroughStyle {
+		styles = append(styles, "Strikeout")
+	}
+	if text.AllCaps == AllCapsStyle {
+		styles = append(styles, "AllCaps")
+	}
+	t.Style = strings.Join(styles, "+")
+	t.RevisionID = text.Revision
+	return t
+}
+
+// fdxFromPara converts an OSF para into an FDX Paragraph
+func fdxFromPara(para *Para) *fdxParagraph {
+	p := new(fdxParagraph)
+	styleName := ""
+	if para.Style != nil {
+		styleName = para.Style.BaseStyleName
+		p.Alignment = fdxAlignment(para.Style.Align)
+		if para.Style.PageBreakBefore == "1" {
+			p.StartsNewPage = "Yes"
+		}
+	}
+	p.Type = fdxTypeName(styleName)
+	p.Number = para.SceneNumber
+	if styleName == SceneHeadingType && (para.PageNumber != "" || para.Synopsis != "") {
+		p.SceneProperties = new(fdxSceneProperties)
+		p.SceneProperties.Page = para.PageNumber
+		if para.Synopsis != "" {
+			// The first line of a synopsis is the scene title, the
+			// rest is its summary (see fdxSynopsis).
+			lines := strings.Split(para.Synopsis, "\n")
+			p.SceneProperties.Title = lines[0]
+			p.SceneProperties.Color = para.SynopsisColor
+			if len(lines) > 1 {
+				p.SceneProperties.Summary = new(fdxContent)
+				for _, line := range lines[1:] {
+					p.SceneProperties.Summary.Paragraph = append(p.SceneProperties.Summary.Paragraph, &fdxParagraph{
+						Type: fdxGeneralType,
+						Text: []*fdxText{{InnerText: line}},
+					})
+				}
+			}
+		}
+	}
+	for _, text := range para.Text {
+		p.Text = append(p.Text, fdxFromText(text))
+	}
+	if len(p.Text) == 0 {
+		p.Text = append(p.Text, new(fdxText))
+	}
+	// Final Draft expects parentheticals to include their parentheses
+	if styleName == ParentheticalType {
+		first, last := p.Text[0], p.Text[len(p.Text)-1]
+		if !strings.HasPrefix(first.InnerText, "(") {
+			first.InnerText = "(" + first.InnerText
+		}
+		if !strings.HasSuffix(last.InnerText, ")") {
+			last.InnerText = last.InnerText + ")"
+		}
+	}
+	return p
+}
+
+// isDialogueStyle reports if a para is part of a dialogue block
+func isDialogueStyle(para *Para) bool {
+	if para.Style == nil {
+		return false
+	}
+	switch para.Style.BaseStyleName {
+	case CharacterType, ParentheticalType, DialogueType:
+		return true
+	}
+	return false
+}
+
+// fdxFromParas converts paras into FDX Paragraphs, dual dialogue (a
+// character marked dualdialogue and the next speaker) is nested in a
+// DualDialogue element.
+func fdxFromParas(paras []*Para) []*fdxParagraph {
+	out := []*fdxParagraph{}
+	for i := 0; i < len(paras); i++ {
+		para := paras[i]
+		if para.IsStructural() {
+			// Final Draft has no sections or boneyard
+			continue
+		}
+		if para.Style == nil || para.Style.BaseStyleName != CharacterType || para.Style.DualDialogue != "1" {
+			out = append(out, fdxFromPara(para))
+			continue
+		}
+		dual := new(fdxContent)
+		characters := 0
+		for ; i < len(paras) && isDialogueStyle(paras[i]); i++ {
+			if paras[i].Style.BaseStyleName == CharacterType {
+				characters++
+				if characters > 2 {
+					break
+				}
+			}
+			dual.Paragraph = append(dual.Paragraph, fdxFromPara(paras[i]))
+		}
+		i--
+		out = append(out, &fdxParagraph{DualDialogue: dual})
+	}
+	return out
+}
+
+// fdxFromStyle converts an OSF style into FDX ElementSettings
+func fdxFromStyle(style *Style, left, right Length) *fdxElementSettings {
+	elem := new(fdxElementSettings)
+	elem.Type = fdxTypeName(style.Name)
+	font := fdxFromText(&Text{
+		Bold:      style.Bold,
+		Italic:    style.Italic,
+		Underline: style.Underline,
+		AllCaps:   style.AllCaps,
+	})
+	font.Font = style.Font
+	font.Size = style.Size
+	elem.FontSpec = font
+	spec := new(fdxParagraph)
+	spec.Alignment = fdxAlignment(style.Align)
+	if spec.Alignment == "" {
+		spec.Alignment = LeftAlignment
+	}
+	leftIndent, rightIndent := left, right
+	if l, err := ParseLength(style.LeftIdent); err == nil {
+		leftIndent += l
+	}
+	if l, err := ParseLength(style.RightIdent); err == nil {
+		rightIndent -= l
+	}
+	spec.LeftIndent = fdxFormatInches(leftIndent)
+	spec.RightIndent = fdxFormatInches(rightIndent)
+	spec.SpaceBefore = strconv.Itoa(int(floatOrDefault(style.SpaceBefore, 0) * 12))
+	spec.Spacing = style.LineSpacing
+	if spec.Spacing == "" {
+		spec.Spacing = "1"
+	}
+	spec.StartsNewPage = "No"
+	if style.PageBreakBefore == "1" {
+		spec.StartsNewPage = "Yes"
+	}
+	elem.ParagraphSpec = spec
+	elem.Behavior = new(fdxBehavior)
+	elem.Behavior.PaginateAs = elem.Type
+	if style.StyleEnter != "" {
+		elem.Behavior.ReturnKey = fdxTypeName(style.StyleEnter)
+	}
+	return elem
+}
+
+// fdxTitlePageFromInfo builds title page paragraphs from a document's
+// Info when it has no title page of its own.
+func fdxTitlePageFromInfo(info *Info) []*Para {
+	paras := []*Para{}
+	add := func(s string, align string) {
+		para := new(Para)
+		para.Style = new(Style)
+		para.Style.BaseStyleName = GeneralType
+		para.Style.Align = align
+		para.Text = []*Text{{InnerText: s}}
+		paras = append(paras, para)
+	}
+	if info.Title != "" {
+		add(info.Title, "center")
+	}
+	if info.WrittenBy != "" {
+		add("Written by", "center")
+		add(info.WrittenBy, "center")
+	}
+	if info.Copyright != "" {
+		add(info.Copyright, "")
+	}
+	if info.Drafts != "" {
+		add(info.Drafts, "")
+	}
+	if info.Contact != "" {
+		add(info.Contact, "")
+	}
+	return paras
+}
+
+// ToFDX renders the document as Final Draft (.fdx) XML
+func (document *OpenScreenplay) ToFDX() ([]byte, error) {
+	fdx := new(fdxDocument)
+	fdx.DocumentType = "Script"
+	fdx.Template = "No"
+	fdx.Version = "3"
+
+	fdx.Content = new(fdxContent)
+	if document.Paragraphs != nil {
+		fdx.Content.Paragraph = fdxFromParas(document.Paragraphs.Para)
+	}
+
+	settings := document.Settings
+	width, height := settings.PageSize()
+	top, bottom, left, right := settings.Margins()
+	fdx.HeaderAndFooter = new(fdxHeaderAndFooter)
+	fdx.HeaderAndFooter.StartingPage = strconv.Itoa(settings.FirstPageNumber())
+	fdx.PageLayout = new(fdxPageLayout)
+	fdx.PageLayout.TopMargin = strconv.Itoa(int(top.Points() + 0.5))
+	fdx.PageLayout.BottomMargin = strconv.Itoa(int(bottom.Points() + 0.5))
+	fdx.PageLayout.PageSize = &fdxPageSize{
+		Width:  fdxFormatInches(width),
+		Height: fdxFormatInches(height),
+	}
+	if settings != nil {
+		fdx.PageLayout.BreakDialogueAndActionAtSentences = fdxYesNo(settings.BreakOnSentences)
+	}
+	if document.Styles != nil {
+		for _, style := range document.Styles.Style {
+			fdx.ElementSettings = append(fdx.ElementSettings, fdxFromStyle(style, left, width-right))
+		}
+	}
+
+	paras := []*Para{}
+	if document.TitlePage != nil {
+		paras = document.TitlePage.Para
+	} else if document.Info != nil {
+		paras = fdxTitlePageFromInfo(document.Info)
+	}
+	if len(paras) > 0 {
+		fdx.TitlePage = new(fdxTitlePage)
+		fdx.TitlePage.Content = new(fdxContent)
+		fdx.TitlePage.Content.Paragraph = fdxFromParas(paras)
+	}
+
+	if lists := document.Lists; lists != nil {
+		st := new(fdxSmartType)
+		if lists.Characters != nil {
+			for _, item := range lists.Characters.Character {
+				st.Characters = append(st.Characters, item.Name)
+			}
+		}
+		if lists.Extensions != nil {
+			for _, item := range lists.Extensions.Extension {
+				st.Extensions = append(st.Extensions, item.Name)
+			}
+		}
+		if lists.SceneIntros != nil {
+			st.SceneIntros = new(fdxSceneIntros)
+			for _, item := range lists.SceneIntros.SceneIntro {
+				st.SceneIntros.SceneIntro = append(st.SceneIntros.SceneIntro, item.Name)
+			}
+		}
+		if lists.Locations != nil {
+			for _, item := range lists.Locations.Location {
+				st.Locations = append(st.Locations, item.Name)
+			}
+		}
+		if lists.SceneTimes != nil {
+			st.TimesOfDay = new(fdxTimesOfDay)
+			if settings != nil {
+				st.TimesOfDay.Separator = settings.SceneTimeSeparator
+			}
+			for _, item := range lists.SceneTimes.SceneTime {
+				st.TimesOfDay.TimeOfDay = append(st.TimesOfDay.TimeOfDay, item.Name)
+			}
+		}
+		if lists.Transitions != nil {
+			for _, item := range lists.Transitions.Transition {
+				st.Transitions = append(st.Transitions, item.Name)
+			}
+		}
+		fdx.SmartType = st
+	}
+
+	if settings != nil {
+		fdx.MoresAndContinueds = new(fdxMoresAndContinueds)
+		fdx.MoresAndContinueds.DialogueBreaks = &fdxDialogueBreaks{
+			AutomaticCharacterContinueds: fdxYesNo(settings.DialogueContinues),
+			DialogueBottom:               settings.MoreText,
+			DialogueTop:                  settings.ContText,
+		}
+		if settings.ContinuedText != "" {
+			fdx.MoresAndContinueds.SceneBreaks = &fdxSceneBreaks{
+				ContinuedNumber: fdxYesNo(settings.NumberContinued),
+				SceneTop:        settings.ContinuedText + ":",
+			}
+		}
+	}
+
+	if document.Lists != nil && document.Lists.RevisionColors != nil {
+		fdx.Revisions = new(fdxRevisions)
+		if settings != nil {
+			fdx.Revisions.ActiveSet = settings.Revision
+			fdx.Revisions.RevisionMode = fdxYesNo(settings.ShowRevisions)
+			if ParseBool(settings.ShowAllRevisions) {
+				fdx.Revisions.RevisionsShown = "All"
+			}
+		}
+		for _, color := range document.Lists.RevisionColors.RevisionColor {
+			fdx.Revisions.Revision = append(fdx.Revisions.Revision, color.toFDXRevision())
+		}
+	}
+
+	src, err := xml.MarshalIndent(fdx, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(DocString+"\n"), src...), nil
+}
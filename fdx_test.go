@@ -0,0 +1,256 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFromFDX(t *testing.T) {
+	for i := 1; i <= 6; i++ {
+		fname := filepath.Join("testdata", fmt.Sprintf("sample-%02d.fdx", i))
+		document, err := ParseFile(fname)
+		if err != nil {
+			t.Errorf("%s, %s", fname, err)
+			continue
+		}
+		if document.Paragraphs == nil || len(document.Paragraphs.Para) == 0 {
+			t.Errorf("%s, expected paragraphs", fname)
+		}
+		if document.Lists == nil || document.Lists.Characters == nil {
+			t.Errorf("%s, expected a character list", fname)
+		}
+		if _, err := document.ToXML(); err != nil {
+			t.Errorf("%s, %s", fname, err)
+		}
+	}
+
+	document, err := ParseFile(filepath.Join("testdata", "sample-01.fdx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		ActionType, "FADE IN:",
+		SceneHeadingType, "EXT. LIBRARY - DAY",
+		ActionType, "A PROGRAMMER typing at an old laptop",
+		CharacterType, "PROGRAMMER",
+		ParentheticalType, "excited",
+		DialogueType, "Eureka!",
+		TransitionType, "FADE TO BLACK.",
+	}
+	for i, para := range document.Paragraphs.Para {
+		if i*2 >= len(expected) {
+			break
+		}
+		if para.Style.BaseStyleName != expected[i*2] || para.PlainText() != expected[i*2+1] {
+			t.Errorf("para %d, expected %s %q, got %s %q", i, expected[i*2], expected[i*2+1], para.Style.BaseStyleName, para.PlainText())
+		}
+	}
+	if document.Info.WrittenBy != "Author's Name" {
+		t.Errorf("expected written by from title page, got %q", document.Info.WrittenBy)
+	}
+	width, height := document.Settings.PageSize()
+	if width != LetterWidth || height != LetterHeight {
+		t.Errorf("expected a letter page, got %s x %s", width, height)
+	}
+	for _, style := range document.Styles.Style {
+		if style.Name == CharacterType && style.LeftIdent != "635" {
+			t.Errorf("expected character indent 635, got %q", style.LeftIdent)
+		}
+	}
+
+	src := []byte(`<?xml version="1.0" encoding="UTF-8" standalone="no" ?>
+<FinalDraft DocumentType="Script" Template="No" Version="3">
+  <Content>
+    <Paragraph Number="12A" Type="Scene Heading">
+      <SceneProperties Length="1/8" Page="3" Title="The argument" Color="#FF0000">
+        <Summary>
+          <Paragraph Type="General"><Text>They fight.</Text></Paragraph>
+        </Summary>
+      </SceneProperties>
+      <Text>INT. KITCHEN - NIGHT</Text>
+    </Paragraph>
+    <Paragraph Type="Action">
+      <Text Style="Bold+Underline">Loud</Text>
+      <Text RevisionID="2"> noises.</Text>
+    </Paragraph>
+    <Paragraph>
+      <DualDialogue>
+        <Paragraph Type="Character"><Text>ANN</Text></Paragraph>
+        <Paragraph Type="Dialogue"><Text>No!</Text></Paragraph>
+        <Paragraph Type="Character"><Text>BOB</Text></Paragraph>
+        <Paragraph Type="Dialogue"><Text>Yes!</Text></Paragraph>
+      </DualDialogue>
+    </Paragraph>
+  </Content>
+  <Revisions ActiveSet="2">
+    <Revision Color="#00000000FFFF" ID="1" Name="Blue" Style=""/>
+    <Revision Color="#FFFF0000FFFF" ID="2" Name="Pink" Style=""/>
+  </Revisions>
+</FinalDraft>`)
+	document, err = FromFDX(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paras := document.Paragraphs.Para
+	if len(paras) != 6 {
+		t.Fatalf("expected 6 paragraphs, got %d", len(paras))
+	}
+	if paras[0].SceneNumber != "12A" || paras[0].PageNumber != "3" {
+		t.Errorf("expected scene 12A on page 3, got %q on %q", paras[0].SceneNumber, paras[0].PageNumber)
+	}
+	if paras[0].Synopsis != "The argument\nThey fight." || paras[0].SynopsisColor != "#FF0000" {
+		t.Errorf("unexpected synopsis %q %q", paras[0].Synopsis, paras[0].SynopsisColor)
+	}
+	if text := paras[1].Text[0]; text.Bold != BoldStyle || text.Underline != UnderlineStyle || text.Italic != "" {
+		t.Errorf("expected bold underline, got %+v", text)
+	}
+	if text := paras[1].Text[1]; text.Revision != "2" {
+		t.Errorf("expected revision 2, got %q", text.Revision)
+	}
+	if paras[2].Style.DualDialogue != "1" || paras[4].Style.DualDialogue != "" {
+		t.Errorf("expected first character of dual dialogue marked")
+	}
+	if document.Settings.RevisionNumber() != 2 || len(document.Lists.RevisionColors.RevisionColor) != 2 {
+		t.Errorf("expected revision 2 of 2 revision colors")
+	}
+	if attrs := document.Lists.RevisionColors.RevisionColor[0].UnknownAttrs; len(attrs) != 1 || attrs[0].Name.Local != "fdx_color" || attrs[0].Value != "#00000000FFFF" {
+		t.Errorf("expected the revision set's color kept, got %+v", attrs)
+	}
+	if !document.Settings.Flag(SceneNumberingFlag) {
+		t.Errorf("expected scene numbering to be on")
+	}
+}
+
+func TestToFDX(t *testing.T) {
+	// FDX -> OSF -> FDX -> OSF should give the same document
+	for i := 1; i <= 6; i++ {
+		fname := filepath.Join("testdata", fmt.Sprintf("sample-%02d.fdx", i))
+		document, err := ParseFile(fname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		src, err := document.ToFDX()
+		if err != nil {
+			t.Errorf("%s, %s", fname, err)
+			continue
+		}
+		other, err := FromFDX(src)
+		if err != nil {
+			t.Errorf("%s, %s", fname, err)
+			continue
+		}
+		// FDX has no UUID, FromFDX generates a new one
+		other.Info.UUID = document.Info.UUID
+		expected, _ := document.ToXML()
+		got, _ := other.ToXML()
+		if string(expected) != string(got) {
+			t.Errorf("%s, FDX round trip changed the document\n%s\n%s", fname, expected, got)
+		}
+
+		// The revision sets keep their colors and marks
+		original, err := os.ReadFile(fname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before, after := new(fdxDocument), new(fdxDocument)
+		if err := xml.Unmarshal(original, before); err != nil {
+			t.Fatal(err)
+		}
+		if err := xml.Unmarshal(src, after); err != nil {
+			t.Fatal(err)
+		}
+		if before.Revisions == nil || after.Revisions == nil || len(before.Revisions.Revision) != len(after.Revisions.Revision) {
+			t.Errorf("%s, expected the revision sets written to FDX", fname)
+			continue
+		}
+		for j, r := range before.Revisions.Revision {
+			if *r != *after.Revisions.Revision[j] {
+				t.Errorf("%s, revision %d, expected %+v, got %+v", fname, j, r, after.Revisions.Revision[j])
+			}
+		}
+	}
+
+	// OSF -> FDX keeps paragraph types, text runs and dual dialogue
+	document, err := ParseFile(filepath.Join("testdata", "OSF-2.0.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := document.ToFDX()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := FromFDX(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(other.Paragraphs.Para) != len(document.Paragraphs.Para) {
+		t.Fatalf("expected %d paragraphs, got %d", len(document.Paragraphs.Para), len(other.Paragraphs.Para))
+	}
+	for i, para := range document.Paragraphs.Para {
+		got := other.Paragraphs.Para[i]
+		if para.Style.BaseStyleName != got.Style.BaseStyleName || para.String() != got.String() {
+			t.Errorf("para %d, expected %s %q, got %s %q", i, para.Style.BaseStyleName, para.String(), got.Style.BaseStyleName, got.String())
+		}
+		if para.Style.DualDialogue != got.Style.DualDialogue {
+			t.Errorf("para %d, expected dualdialogue %q, got %q", i, para.Style.DualDialogue, got.Style.DualDialogue)
+		}
+		if para.SceneNumber != got.SceneNumber {
+			t.Errorf("para %d, expected scene number %q, got %q", i, para.SceneNumber, got.SceneNumber)
+		}
+	}
+	if !bytes.Contains(src, []byte(`<Text Style="Bold+Italic+Underline">Bold-italic-underlined text</Text>`)) {
+		t.Errorf("expected text run formatting in FDX")
+	}
+	// Revision colors from Fade In get Final Draft's colors by name
+	if !bytes.Contains(src, []byte(`<Revision Color="#00000000FFFF" FullRevision="No" ID="1" Mark="*" Name="Blue" PageColor="#C6C6EDEDFEFE" Style=""></Revision>`)) {
+		t.Errorf("expected the Blue revision set's colors in FDX")
+	}
+
+	// Info is used for the title page when there isn't one
+	document = NewOpenScreenplay20()
+	document.Info = &Info{Title: "Night Shift", WrittenBy: "Jane Doe"}
+	src, err = document.ToFDX()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err = FromFDX(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Info.Title != "Night Shift" || other.Info.WrittenBy != "Jane Doe" {
+		t.Errorf("expected title page from info, got %+v", other.Info)
+	}
+}
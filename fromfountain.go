@@ -30,26 +30,345 @@
 package osf
 
 import (
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	// My Packages
 	"github.com/rsdoiel/fountain"
 )
 
+// emphasisToken is either literal text or a run of Fountain emphasis
+// markers ("*" or "_") waiting to be matched.
+type emphasisToken struct {
+	text      string
+	marker    byte
+	count     int
+	canOpen   bool
+	canClose  bool
+	bold      bool
+	italic    bool
+	underline bool
+}
+
+// emphasisTokens splits a single line into literal text and marker runs.
+// A backslash escapes "*", "_" and itself.
+func emphasisTokens(line string) []*emphasisToken {
+	tokens := []*emphasisToken{}
+	literal := []byte{}
+	flush := func() {
+		if len(literal) > 0 {
+			tokens = append(tokens, &emphasisToken{text: string(literal)})
+			literal = []byte{}
+		}
+	}
+	isSpace := func(i int) bool {
+		return i < 0 || i >= len(line) || line[i] == ' ' || line[i] == '\t'
+	}
+	// isWord reports if the characters either side of line[i:j] are
+	// letters or digits, e.g. the underscores in snake_case_name
+	isWord := func(i, j int) bool {
+		before, _ := utf8.DecodeLastRuneInString(line[:i])
+		after, _ := utf8.DecodeRuneInString(line[j:])
+		return (unicode.IsLetter(before) || unicode.IsDigit(before)) &&
+			(unicode.IsLetter(after) || unicode.IsDigit(after))
+	}
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line) && strings.IndexByte(`\*_`, line[i+1]) >= 0:
+			i++
+			literal = append(literal, line[i])
+		case c == '*' || c == '_':
+			flush()
+			j := i
+			for j < len(line) && line[j] == c {
+				j++
+			}
+			token := &emphasisToken{
+				text:     line[i:j],
+				marker:   c,
+				count:    j - i,
+				canOpen:  !isSpace(j),
+				canClose: !isSpace(i - 1),
+			}
+			// Underscores inside a word aren't emphasis
+			if c == '_' && isWord(i, j) {
+				token.canOpen, token.canClose = false, false
+			}
+			tokens = append(tokens, token)
+			i = j - 1
+		default:
+			literal = append(literal, c)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// emphasisLine matches the marker runs in a line, marks the text between
+// them and turns unmatched markers back into literal text.
+func emphasisLine(line string) []*emphasisToken {
+	tokens := emphasisTokens(line)
+	openers := []int{}
+	for j, closer := range tokens {
+		if closer.marker == 0 {
+			continue
+		}
+		for closer.canClose && closer.count > 0 {
+			k := len(openers) - 1
+			for k >= 0 && tokens[openers[k]].marker != closer.marker {
+				k--
+			}
+			if k < 0 {
+				break
+			}
+			i := openers[k]
+			opener := tokens[i]
+			use := 1
+			if closer.marker == '*' && opener.count >= 2 && closer.count >= 2 {
+				use = 2
+			}
+			for _, token := range tokens[i+1 : j] {
+				switch {
+				case closer.marker == '_':
+					token.underline = true
+				case use == 2:
+					token.bold = true
+				default:
+					token.italic = true
+				}
+			}
+			opener.count -= use
+			closer.count -= use
+			// Markers opened inside the match can no longer close
+			openers = openers[0 : k+1]
+			if opener.count == 0 {
+				openers = openers[0:k]
+			}
+		}
+		if closer.canOpen && closer.count > 0 {
+			openers = append(openers, j)
+		}
+	}
+	// Unmatched markers are literal text, matches use the inner markers
+	for _, token := range tokens {
+		if token.marker != 0 {
+			token.text = strings.Repeat(string(token.marker), token.count)
+		}
+	}
+	return tokens
+}
+
+// StringToTextArray parses a string holding Fountain emphasis into Text
+// runs, e.g. "*italic*", "**bold**", "***bold italic***" and "_underline_".
+// Emphasis may nest but does not carry across line breaks, "\*", "\_" and
+// "\\" escape the literal characters. A string without emphasis comes
+// back as a single Text.
 func StringToTextArray(s string) []*Text {
 	var a []*Text
 
-	//FIXME: if string has embedded formatting split into parts and set format attributes
-	// (e.g. text.Bold, text.Italic) as needed.
-	text := new(Text)
-	text.InnerText = s
-	a = append(a, text)
+	add := func(s string, bold, italic, underline bool) {
+		text := new(Text)
+		if bold {
+			text.Bold = BoldStyle
+		}
+		if italic {
+			text.Italic = ItalicStyle
+		}
+		if underline {
+			text.Underline = UnderlineStyle
+		}
+		if len(a) > 0 {
+			last := a[len(a)-1]
+			if last.Bold == text.Bold && last.Italic == text.Italic && last.Underline == text.Underline {
+				last.InnerText += s
+				return
+			}
+		}
+		text.InnerText = s
+		a = append(a, text)
+	}
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			// The line break stays with the run before it
+			if len(a) > 0 {
+				a[len(a)-1].InnerText += "\n"
+			} else {
+				add("\n", false, false, false)
+			}
+		}
+		for _, token := range emphasisLine(line) {
+			if token.text != "" {
+				add(token.text, token.bold, token.italic, token.underline)
+			}
+		}
+	}
+	if len(a) == 0 {
+		a = append(a, new(Text))
+	}
 	return a
 }
 
-// AddInfo parses a map[string]string and updates/adds Info struct to document as needed.
+// fountainValue cleans up a Fountain title page value, the parser keeps
+// the space after the key and the indenting of continued lines.
+func fountainValue(s string) string {
+	lines := []string{}
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fountainTitleSplit splits a title page value at the first blank line,
+// anything after it is script the parser took to be title page.
+func fountainTitleSplit(s string) (string, string) {
+	lines := strings.Split(s, "\n")
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			return strings.Join(lines[:i], "\n"), strings.TrimSpace(strings.Join(lines[i+1:], "\n"))
+		}
+	}
+	return s, ""
+}
+
+// fountainIsTitleKey reports if s can be a title page key, e.g. "Draft
+// date", lines forced as some other element can't
+func fountainIsTitleKey(s string) bool {
+	s = strings.TrimSpace(s)
+	return s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != ' ' && r != '_' && r != '-'
+	}) < 0
+}
+
+// fountainTitlePara creates a title page paragraph
+func fountainTitlePara(bookmark string, s string, align string) *Para {
+	para := new(Para)
+	para.Bookmark = bookmark
+	para.Style = new(Style)
+	para.Style.BaseStyleName = GeneralType
+	para.Style.Align = align
+	para.Text = StringToTextArray(s)
+	return para
+}
+
+// fountainLines applies fn to each line of s
+func fountainLines(s string, fn func(string) string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = fn(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fountainUnforce removes a leading force character (e.g. "!" or "@")
+func fountainUnforce(s string, force string) string {
+	return strings.TrimPrefix(strings.TrimSpace(s), force)
+}
+
+// fountainCentered reports if s is centered text, e.g. ">THE END<"
+func fountainCentered(s string) bool {
+	s = strings.TrimSpace(s)
+	return strings.HasPrefix(s, ">") && strings.HasSuffix(s, "<")
+}
+
+// fountainIsTransition reports if s is an unforced transition, a single
+// line in upper case ending in "TO:"
+func fountainIsTransition(s string) bool {
+	return !strings.Contains(s, "\n") && strings.HasSuffix(s, "TO:") &&
+		strings.ToUpper(s) == s
+}
+
+// fountainInlineNotes removes "[[notes]]" from s, returning the text
+// and the notes
+func fountainInlineNotes(s string) (string, []string) {
+	notes := []string{}
+	for {
+		i := strings.Index(s, "[[")
+		if i < 0 {
+			break
+		}
+		j := strings.Index(s[i:], "]]")
+		if j < 0 {
+			break
+		}
+		notes = append(notes, strings.TrimSpace(s[i+2:i+j]))
+		before, after := s[:i], s[i+j+2:]
+		if strings.HasSuffix(before, " ") && (after == "" || strings.HasPrefix(after, " ") || strings.HasPrefix(after, "\n")) {
+			before = before[:len(before)-1]
+		}
+		s = before + after
+	}
+	return s, notes
+}
+
+// appendNote adds a note to a paragraph's note, one per line
+func appendNote(para *Para, note string) {
+	if note = strings.TrimSpace(note); note == "" {
+		return
+	}
+	if para.Note != "" {
+		para.Note += "\n"
+	}
+	para.Note += note
+}
+
+// fountainSceneHeading splits a scene heading into its text and scene
+// number, e.g. ".Rooftop #1A#" becomes "Rooftop" and "1A"
+func fountainSceneHeading(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, ".") && !strings.HasPrefix(s, "..") {
+		s = s[1:]
+	}
+	sceneNumber := ""
+	if strings.HasSuffix(s, "#") {
+		if i := strings.LastIndex(s[:len(s)-1], "#"); i >= 0 {
+			sceneNumber = strings.TrimSpace(s[i+1 : len(s)-1])
+			s = strings.TrimSpace(s[:i])
+		}
+	}
+	return s, sceneNumber
+}
+
+// FromFountain populates the document from a parsed Fountain screenplay.
+// Title page keys fill in Info and the title page, script elements become
+// paragraphs styled as the matching OSF element. Sections and boneyard
+// become Section and Boneyard paragraphs and their styles are added to
+// the document (see AddStructuralStyles).
 func (document *OpenScreenplay) FromFountain(screenplay *fountain.Fountain) {
-	if screenplay.TitlePage != nil {
+	// The parser reads everything up to the first scene heading or
+	// transition as title page. The title page ends at the first blank
+	// line, what follows it is script so it is parsed again.
+	titlePage, front, ended := []*fountain.Element{}, []string{}, false
+	for i, elem := range screenplay.TitlePage {
+		switch {
+		case ended:
+			front = append(front, elem.Name+":"+elem.Content)
+		case i == 0 && elem.Name == "Unknown":
+			// The script starts without a title page
+			front, ended = append(front, elem.Content), true
+		case i == 0 && !fountainIsTitleKey(elem.Name):
+			// e.g. a forced action "!Note: ..."
+			front, ended = append(front, elem.Name+":"+elem.Content), true
+		default:
+			value, rest := fountainTitleSplit(elem.Content)
+			titlePage = append(titlePage, &fountain.Element{Type: elem.Type, Name: elem.Name, Content: value})
+			if ended = value != elem.Content; rest != "" {
+				front = append(front, rest)
+			}
+		}
+	}
+	elements := screenplay.Elements
+	if s := strings.TrimSpace(strings.Join(front, "\n")); s != "" {
+		if screenplay, err := fountain.Parse([]byte("===\n" + s + "\n\n")); err == nil && len(screenplay.Elements) > 1 {
+			elements = append(screenplay.Elements[1:], elements...)
+		}
+	}
+
+	if len(titlePage) > 0 {
 		// Build the Info section
 		if document.Info == nil {
 			document.Info = new(Info)
@@ -60,74 +379,248 @@ func (document *OpenScreenplay) FromFountain(screenplay *fountain.Fountain) {
 		}
 
 		// NOTE: build a map of elements that will belong to Info.
-		for _, elem := range screenplay.TitlePage {
-			switch strings.ToLower(elem.Name) {
+		for _, elem := range titlePage {
+			value := fountainValue(elem.Content)
+			switch strings.ToLower(strings.TrimSpace(elem.Name)) {
 			case "title":
-				document.Info.Title = elem.Content
-				para := new(Para)
-				para.Bookmark = "Title"
-				para.Text = StringToTextArray(elem.Content)
-				document.TitlePage.Para = append(document.TitlePage.Para, para)
+				document.Info.Title = value
+				document.TitlePage.Para = append(document.TitlePage.Para, fountainTitlePara("Title", value, "center"))
 			case "credit":
-				document.Info.WrittenBy = elem.Content
-				para := new(Para)
-				para.Bookmark = "Credits"
-				para.Text = StringToTextArray(elem.Content)
-				document.TitlePage.Para = append(document.TitlePage.Para, para)
-			case "author":
-				document.Info.WrittenBy = elem.Content
-				para := new(Para)
-				para.Bookmark = "Author"
-				para.Text = StringToTextArray(elem.Content)
-				document.TitlePage.Para = append(document.TitlePage.Para, para)
+				document.TitlePage.Para = append(document.TitlePage.Para, fountainTitlePara("Credits", value, "center"))
+			case "author", "authors":
+				document.Info.WrittenBy = value
+				document.TitlePage.Para = append(document.TitlePage.Para, fountainTitlePara("Author", value, "center"))
 			case "copyright":
-				document.Info.Copyright = elem.Content
-				para := new(Para)
-				para.Bookmark = "Copyright"
-				para.Text = StringToTextArray(elem.Content)
-				document.TitlePage.Para = append(document.TitlePage.Para, para)
+				document.Info.Copyright = value
+				document.TitlePage.Para = append(document.TitlePage.Para, fountainTitlePara("Copyright", value, ""))
 			case "draft date":
-				document.Info.Drafts = elem.Content
-				para := new(Para)
-				para.Bookmark = "Drafts"
-				para.Text = StringToTextArray(elem.Content)
-				document.TitlePage.Para = append(document.TitlePage.Para, para)
+				document.Info.Drafts = value
+				document.TitlePage.Para = append(document.TitlePage.Para, fountainTitlePara("Drafts", value, ""))
 			case "contact":
-				document.Info.Contact = elem.Content
-				para := new(Para)
-				para.Bookmark = "Contact"
-				para.Text = StringToTextArray(elem.Content)
-				document.TitlePage.Para = append(document.TitlePage.Para, para)
+				document.Info.Contact = value
+				document.TitlePage.Para = append(document.TitlePage.Para, fountainTitlePara("Contact", value, ""))
 			case "source":
-				para := new(Para)
-				para.Bookmark = "Source"
-				para.Text = StringToTextArray(elem.Content)
-				document.TitlePage.Para = append(document.TitlePage.Para, para)
+				document.TitlePage.Para = append(document.TitlePage.Para, fountainTitlePara("Source", value, "center"))
 			case "story by":
-				para := new(Para)
-				para.Bookmark = "Story By"
-				para.Text = StringToTextArray(elem.Content)
-				document.TitlePage.Para = append(document.TitlePage.Para, para)
+				document.TitlePage.Para = append(document.TitlePage.Para, fountainTitlePara("Story By", value, "center"))
 			case "uuid":
-				document.Info.UUID = elem.Content
+				document.Info.UUID = value
 			case "page_count":
-				document.Info.PageCount = elem.Content
+				document.Info.PageCount = value
 			case "title_format":
-				document.Info.TitleFormat = elem.Content
+				document.Info.TitleFormat = value
 			}
 		}
 	}
 
-	if screenplay.Elements != nil {
+	if elements != nil {
 		// Populate the Paragraphs array
 		if document.Paragraphs == nil {
 			document.Paragraphs = new(Paragraphs)
 		}
-		for _, elem := range screenplay.Elements {
+		var (
+			prev          *Para
+			lastCharacter *Para
+			anchor        *Para
+			sawEmpty      bool
+			pageBreak     bool
+			notes         []string
+			synopsis      []string
+		)
+		// addStructural adds a paragraph, notes and synopses found before
+		// any paragraph go to the first one
+		addStructural := func(style string, s string) *Para {
 			para := new(Para)
-			para.Bookmark = elem.TypeName()
-			para.Text = StringToTextArray(elem.Content)
+			para.Style = new(Style)
+			para.Style.BaseStyleName = style
+			para.Text = StringToTextArray(s)
+			for _, note := range notes {
+				appendNote(para, note)
+			}
+			para.Synopsis = strings.Join(synopsis, "\n")
+			notes, synopsis = nil, nil
 			document.Paragraphs.Para = append(document.Paragraphs.Para, para)
+			prev = para
+			return para
+		}
+		add := func(style string, s string) *Para {
+			s, inline := fountainInlineNotes(s)
+			para := addStructural(style, s)
+			for _, note := range inline {
+				appendNote(para, note)
+			}
+			if pageBreak {
+				para.Style.PageBreakBefore = "1"
+				pageBreak = false
+			}
+			if style == SceneHeadingType {
+				anchor = para
+			}
+			return para
+		}
+		// continuesDialogue reports if the element is on the line after
+		// dialogue, the parser only recognizes the first line of dialogue.
+		continuesDialogue := func() bool {
+			return !sawEmpty && prev != nil && prev.Style != nil &&
+				(prev.Style.BaseStyleName == DialogueType || prev.Style.BaseStyleName == ParentheticalType || prev.Style.BaseStyleName == CharacterType)
+		}
+		// addDialogue adds a line to the current dialogue paragraph
+		addDialogue := func(s string) {
+			if prev.Style.BaseStyleName == DialogueType && len(prev.Text) > 0 {
+				s, inline := fountainInlineNotes(s)
+				prev.Text[len(prev.Text)-1].InnerText += "\n"
+				prev.Text = append(prev.Text, StringToTextArray(s)...)
+				for _, note := range inline {
+					appendNote(prev, note)
+				}
+			} else {
+				add(DialogueType, s)
+			}
+		}
+		for i := 0; i < len(elements); i++ {
+			elem := elements[i]
+			content := elem.Content
+			trimmed := strings.TrimSpace(content)
+			if strings.HasPrefix(trimmed, "/*") {
+				// The boneyard runs to the closing "*/", the parser
+				// doesn't know about it so collect the lines.
+				lines := []string{strings.TrimPrefix(trimmed, "/*")}
+				for !strings.HasSuffix(strings.TrimSpace(lines[len(lines)-1]), "*/") && i+1 < len(elements) {
+					i++
+					lines = append(lines, elements[i].Content)
+				}
+				s := strings.TrimSpace(strings.Join(lines, "\n"))
+				addStructural(BoneyardType, strings.TrimSpace(strings.TrimSuffix(s, "*/")))
+				sawEmpty = false
+				continue
+			}
+			switch elem.Type {
+			case fountain.NoteType:
+				// Notes spanning more than two lines are broken up by the
+				// parser so collect the lines up to the closing "]]".
+				lines := []string{content}
+				for !strings.HasSuffix(strings.TrimSpace(lines[len(lines)-1]), "]]") && i+1 < len(elements) {
+					i++
+					lines = append(lines, elements[i].Content)
+				}
+				note := strings.TrimSpace(strings.Join(lines, "\n"))
+				note = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(note, "[["), "]]"))
+				if prev != nil {
+					appendNote(prev, note)
+				} else {
+					notes = append(notes, note)
+				}
+			case fountain.SectionType:
+				for _, line := range strings.Split(content, "\n") {
+					line = strings.TrimSpace(line)
+					title := strings.TrimLeft(line, "#")
+					level := len(line) - len(title)
+					if title = strings.TrimSpace(title); title != "" {
+						para := addStructural(SectionType, title)
+						para.Bookmark = title
+						para.Level = strconv.Itoa(level)
+						anchor = para
+					}
+				}
+			case fountain.SynopsisType:
+				// A synopsis belongs to the section or scene before it
+				lines := []string{}
+				for _, line := range strings.Split(content, "\n") {
+					if line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "=")); line != "" {
+						lines = append(lines, line)
+					}
+				}
+				if anchor != nil {
+					if anchor.Synopsis != "" {
+						lines = append([]string{anchor.Synopsis}, lines...)
+					}
+					anchor.Synopsis = strings.Join(lines, "\n")
+				} else {
+					synopsis = append(synopsis, lines...)
+				}
+			case fountain.EmptyType:
+				sawEmpty = true
+				continue
+			case fountain.PageFeed:
+				// A page break before any paragraphs ends the title page
+				if len(document.Paragraphs.Para) > 0 {
+					pageBreak = true
+				}
+			case fountain.SceneHeadingType:
+				if continuesDialogue() {
+					// Dialogue containing " -" is read as a scene heading
+					addDialogue(content)
+					break
+				}
+				upper := strings.ToUpper(strings.TrimSpace(content))
+				switch upper {
+				case "FADE IN:":
+					add(TransitionType, strings.TrimSpace(content))
+				case "THE END", "THE END.", "LA FIN", "LA FIN.":
+					add(ActionType, strings.TrimSpace(content))
+				default:
+					heading, sceneNumber := fountainSceneHeading(content)
+					para := add(SceneHeadingType, heading)
+					para.SceneNumber = sceneNumber
+				}
+			case fountain.CharacterType:
+				name := strings.TrimSpace(fountainUnforce(content, "@"))
+				if strings.HasSuffix(name, "^") {
+					name = strings.TrimSpace(strings.TrimSuffix(name, "^"))
+					if lastCharacter != nil {
+						lastCharacter.Style.DualDialogue = "1"
+					}
+				}
+				lastCharacter = add(CharacterType, name)
+			case fountain.ParentheticalType:
+				s := strings.TrimSpace(content)
+				if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+					s = s[1 : len(s)-1]
+				}
+				add(ParentheticalType, s)
+			case fountain.DialogueType:
+				add(DialogueType, fountainLines(content, func(line string) string {
+					if strings.TrimSpace(line) == "" {
+						return ""
+					}
+					return line
+				}))
+			case fountain.LyricType:
+				add(SingingType, fountainLines(content, func(line string) string {
+					return strings.TrimPrefix(strings.TrimSpace(line), "~")
+				}))
+			case fountain.ActionType, fountain.TransitionType, fountain.CenterAlignment, fountain.GeneralTextType:
+				s := strings.TrimSpace(content)
+				switch {
+				case fountainCentered(strings.TrimPrefix(s, "!")):
+					// Centered text can be read as action, a character or
+					// a transition
+					s = strings.TrimPrefix(s, "!")
+					para := add(ActionType, fountainLines(s, func(line string) string {
+						line = strings.TrimSpace(line)
+						return strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, ">"), "<"))
+					}))
+					para.Style.Align = "center"
+				case strings.HasPrefix(s, ">"):
+					// Forced transitions ending in ":" are read as action
+					add(TransitionType, strings.TrimSpace(strings.TrimPrefix(s, ">")))
+				case elem.Type == fountain.TransitionType:
+					add(TransitionType, s)
+				case fountainIsTransition(s):
+					// Transitions like "CUT TO:" are read as action
+					add(TransitionType, s)
+				case continuesDialogue():
+					// The lines after the first line of dialogue are
+					// read as action
+					addDialogue(content)
+				default:
+					add(ActionType, fountainLines(content, func(line string) string {
+						return strings.TrimPrefix(line, "!")
+					}))
+				}
+			}
+			sawEmpty = false
 		}
 	}
+	document.AddStructuralStyles()
 }
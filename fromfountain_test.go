@@ -0,0 +1,227 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	// My Packages
+	"github.com/rsdoiel/fountain"
+)
+
+// fountainStyle reduces a style to the element Fountain can express
+func fountainStyle(para *Para) string {
+	if para.Style == nil {
+		return ActionType
+	}
+	switch para.Style.BaseStyleName {
+	case SceneHeadingType, CharacterType, ParentheticalType, DialogueType, TransitionType, SingingType:
+		return para.Style.BaseStyleName
+	}
+	return ActionType
+}
+
+func TestStringToTextArray(t *testing.T) {
+	// runs renders Text runs as "flags:text" pairs, e.g. "bi:both"
+	runs := func(a []*Text) string {
+		parts := []string{}
+		for _, text := range a {
+			flags := ""
+			if text.Bold == BoldStyle {
+				flags += "b"
+			}
+			if text.Italic == ItalicStyle {
+				flags += "i"
+			}
+			if text.Underline == UnderlineStyle {
+				flags += "u"
+			}
+			parts = append(parts, flags+":"+text.InnerText)
+		}
+		return strings.Join(parts, "|")
+	}
+	for src, expected := range map[string]string{
+		"":                                 ":",
+		"plain text":                       ":plain text",
+		"*italic*":                         "i:italic",
+		"**bold**":                         "b:bold",
+		"***bold italic***":                "bi:bold italic",
+		"_underline_":                      "u:underline",
+		"a **bold _and underlined_** word": ":a |b:bold |bu:and underlined|: word",
+		"**bold *italic* bold**":           "b:bold |bi:italic|b: bold",
+		"_***all three***_":                "biu:all three",
+		`\*not italic\* 2\_3 \\`:           `:*not italic* 2_3 \`,
+		"2 * 3 * 4":                        ":2 * 3 * 4",
+		"*unclosed":                        ":*unclosed",
+		"**bold\nbold**":                   ":**bold\nbold**",
+		"**one**\n**two**":                 "b:one\ntwo",
+		"*a* and **b**\nplain":             "i:a|: and |b:b\n|:plain",
+		"****four****":                     "b:four",
+		"snake_case_name here":             ":snake_case_name here",
+		"_under_score_":                    "u:under_score",
+	} {
+		if got := runs(StringToTextArray(src)); got != expected {
+			t.Errorf("%q, expected %q, got %q", src, expected, got)
+		}
+	}
+}
+
+func TestFromFountain(t *testing.T) {
+	screenplay, err := fountain.ParseFile(filepath.Join("testdata", "sample-02.fountain"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	document := NewOpenScreenplay20()
+	document.FromFountain(screenplay)
+	if document.Info.Title != "TITLE" {
+		t.Errorf("expected title %q, got %q", "TITLE", document.Info.Title)
+	}
+	if document.Info.Drafts != "Draft\ninformation" {
+		t.Errorf("expected drafts %q, got %q", "Draft\ninformation", document.Info.Drafts)
+	}
+	expected := []string{
+		ActionType, SceneHeadingType, ActionType, TransitionType,
+		SceneHeadingType, ActionType, CharacterType, ParentheticalType,
+		DialogueType, TransitionType, SceneHeadingType, ActionType,
+		CharacterType, ParentheticalType, DialogueType, TransitionType,
+	}
+	if len(document.Paragraphs.Para) != len(expected) {
+		t.Fatalf("expected %d paragraphs, got %d", len(expected), len(document.Paragraphs.Para))
+	}
+	for i, para := range document.Paragraphs.Para {
+		if para.Style == nil || para.Style.BaseStyleName != expected[i] {
+			t.Errorf("paragraph %d, expected %q, got %+v", i, expected[i], para.Style)
+		}
+		if para.Bookmark != "" {
+			t.Errorf("paragraph %d, unexpected bookmark %q", i, para.Bookmark)
+		}
+	}
+	if s := document.Paragraphs.Para[7].PlainText(); s != "drowsy" {
+		t.Errorf("expected parenthetical %q, got %q", "drowsy", s)
+	}
+	if s := document.Paragraphs.Para[15].PlainText(); s != "FADE TO BLACK." {
+		t.Errorf("expected transition %q, got %q", "FADE TO BLACK.", s)
+	}
+
+	// OSF to Fountain and back should keep the paragraph styles
+	fnames, _ := filepath.Glob(filepath.Join("testdata", "sample-*.osf"))
+	for _, fname := range fnames {
+		original, err := ParseFile(fname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		screenplay, err := fountain.Parse([]byte(original.ToFountain()))
+		if err != nil {
+			t.Errorf("%s, %s", fname, err)
+			continue
+		}
+		document := NewOpenScreenplay20()
+		document.FromFountain(screenplay)
+		paras := []*Para{}
+		for _, para := range original.Paragraphs.Para {
+			if strings.TrimSpace(para.PlainText()) != "" {
+				paras = append(paras, para)
+			}
+		}
+		if len(paras) != len(document.Paragraphs.Para) {
+			t.Errorf("%s, expected %d paragraphs, got %d", fname, len(paras), len(document.Paragraphs.Para))
+			continue
+		}
+		for i, para := range document.Paragraphs.Para {
+			if expected, got := fountainStyle(paras[i]), fountainStyle(para); expected != got {
+				t.Errorf("%s, paragraph %d, expected %q, got %q", fname, i, expected, got)
+			}
+			if !isDialogueStyle(para) && (para.Note != paras[i].Note || para.Synopsis != paras[i].Synopsis) {
+				t.Errorf("%s, paragraph %d, expected note %q and synopsis %q, got %q and %q", fname, i, paras[i].Note, paras[i].Synopsis, para.Note, para.Synopsis)
+			}
+			if expected, got := strings.TrimSpace(fountainText(paras[i])), strings.TrimSpace(fountainText(para)); expected != got {
+				t.Errorf("%s, paragraph %d, expected %q, got %q", fname, i, expected, got)
+			}
+		}
+	}
+
+	src := []byte(`Title: Night Shift
+
+.Rooftop #1A#
+
+>THE END<
+
+ANN
+(quietly)
+No!
+
+===
+
+@Bob ^
+Yes!
+Maybe -- later.
+
+~La la la
+~La la
+
+> CUT TO:
+`)
+	screenplay, err = fountain.Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	document = NewOpenScreenplay20()
+	document.FromFountain(screenplay)
+	paras := document.Paragraphs.Para
+	if len(paras) != 9 {
+		t.Fatalf("expected 9 paragraphs, got %d", len(paras))
+	}
+	if s := paras[0].PlainText(); s != "Rooftop" || paras[0].SceneNumber != "1A" {
+		t.Errorf("expected scene heading %q #1A#, got %q #%s#", "Rooftop", s, paras[0].SceneNumber)
+	}
+	if s := paras[1].PlainText(); s != "THE END" || paras[1].Style.Align != "center" {
+		t.Errorf("expected centered %q, got %q, %q", "THE END", s, paras[1].Style.Align)
+	}
+	if paras[2].Style.DualDialogue != "1" {
+		t.Errorf("expected ANN to start dual dialogue")
+	}
+	if s := paras[3].PlainText(); s != "quietly" {
+		t.Errorf("expected parenthetical %q, got %q", "quietly", s)
+	}
+	if s := paras[5].PlainText(); s != "Bob" || paras[5].Style.PageBreakBefore != "1" {
+		t.Errorf("expected Bob after a page break, got %q, %+v", s, paras[5].Style)
+	}
+	if s := paras[6].PlainText(); s != "Yes!\nMaybe -- later." || paras[6].Style.BaseStyleName != DialogueType {
+		t.Errorf("expected dialogue %q, got %q, %+v", "Yes!\nMaybe -- later.", s, paras[6].Style)
+	}
+	if s := paras[7].PlainText(); s != "La la la\nLa la" || paras[7].Style.BaseStyleName != SingingType {
+		t.Errorf("expected singing %q, got %q, %+v", "La la la\nLa la", s, paras[7].Style)
+	}
+	if s := paras[8].PlainText(); s != "CUT TO:" || paras[8].Style.BaseStyleName != TransitionType {
+		t.Errorf("expected transition %q, got %q, %+v", "CUT TO:", s, paras[8].Style)
+	}
+}
@@ -0,0 +1,201 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"strings"
+)
+
+// newNames returns the names found that aren't already listed, ignoring
+// case, in the order they were found
+func newNames(listed []string, found []string) []string {
+	seen := map[string]bool{}
+	for _, name := range listed {
+		seen[strings.ToUpper(strings.TrimSpace(name))] = true
+	}
+	names := []string{}
+	for _, name := range found {
+		key := strings.ToUpper(strings.TrimSpace(name))
+		if key != "" && !seen[key] {
+			seen[key] = true
+			names = append(names, strings.TrimSpace(name))
+		}
+	}
+	return names
+}
+
+// addNames calls add with each name found that isn't already listed,
+// ignoring case, in the order they were found
+func addNames(listed []string, found []string, add func(name string)) {
+	for _, name := range newNames(listed, found) {
+		add(name)
+	}
+}
+
+// names returns the names of the characters listed
+func (characters *Characters) names() []string {
+	names := []string{}
+	if characters != nil {
+		for _, item := range characters.Character {
+			names = append(names, item.Name)
+		}
+	}
+	return names
+}
+
+// names returns the names of the locations listed
+func (locations *Locations) names() []string {
+	names := []string{}
+	if locations != nil {
+		for _, item := range locations.Location {
+			names = append(names, item.Name)
+		}
+	}
+	return names
+}
+
+// names returns the names of the scene intros listed
+func (intros *SceneIntros) names() []string {
+	names := []string{}
+	if intros != nil {
+		for _, item := range intros.SceneIntro {
+			names = append(names, item.Name)
+		}
+	}
+	return names
+}
+
+// names returns the names of the scene times listed
+func (times *SceneTimes) names() []string {
+	names := []string{}
+	if times != nil {
+		for _, item := range times.SceneTime {
+			names = append(names, item.Name)
+		}
+	}
+	return names
+}
+
+// names returns the names of the extensions listed
+func (extensions *Extensions) names() []string {
+	names := []string{}
+	if extensions != nil {
+		for _, item := range extensions.Extension {
+			names = append(names, item.Name)
+		}
+	}
+	return names
+}
+
+// names returns the names of the transitions listed
+func (transitions *Transitions) names() []string {
+	names := []string{}
+	if transitions != nil {
+		for _, item := range transitions.Transition {
+			names = append(names, item.Name)
+		}
+	}
+	return names
+}
+
+// RebuildLists fills in the lists of characters, locations, scene intros,
+// scene times, extensions and transitions from the screenplay, e.g. for
+// a document converted from Fountain. Characters are the speakers of
+// dialogue, locations, intros and times come from the scene headings
+// (see ParseSceneHeading), extensions from the character cues (other
+// than the cont_text added when dialogue continues) and transitions from
+// the transition paragraphs. Entries already listed are kept and new
+// ones are added after them in the order they appear.
+func (document *OpenScreenplay) RebuildLists() {
+	characters, locations, intros, times, extensions, transitions := []string{}, []string{}, []string{}, []string{}, []string{}, []string{}
+	contLabel := strings.ToUpper(strings.TrimSpace(document.Settings.ContLabel()))
+	for _, scene := range document.Scenes() {
+		if heading := scene.HeadingParts; heading != nil {
+			locations = append(locations, heading.Place())
+			intros = append(intros, heading.Intro)
+			times = append(times, heading.Time)
+		}
+		blocks, action := scene.split()
+		for _, block := range blocks {
+			characters = append(characters, block.Character)
+			for _, extension := range cueExtensionRE.FindAllString(block.Cue.PlainText(), -1) {
+				if extension = strings.ToUpper(extension); extension != contLabel {
+					extensions = append(extensions, extension)
+				}
+			}
+		}
+		for _, para := range action {
+			if paraStyleName(para) == TransitionType {
+				transitions = append(transitions, strings.ToUpper(strings.TrimSpace(para.PlainText())))
+			}
+		}
+	}
+
+	if document.Lists == nil {
+		document.Lists = new(Lists)
+	}
+	lists := document.Lists
+	addNames(lists.Characters.names(), characters, func(name string) {
+		if lists.Characters == nil {
+			lists.Characters = new(Characters)
+		}
+		lists.Characters.Character = append(lists.Characters.Character, &Character{Name: name})
+	})
+	addNames(lists.Locations.names(), locations, func(name string) {
+		if lists.Locations == nil {
+			lists.Locations = new(Locations)
+		}
+		lists.Locations.Location = append(lists.Locations.Location, &Location{Name: name})
+	})
+	addNames(lists.SceneIntros.names(), intros, func(name string) {
+		if lists.SceneIntros == nil {
+			lists.SceneIntros = new(SceneIntros)
+		}
+		lists.SceneIntros.SceneIntro = append(lists.SceneIntros.SceneIntro, &SceneIntro{Name: name})
+	})
+	addNames(lists.SceneTimes.names(), times, func(name string) {
+		if lists.SceneTimes == nil {
+			lists.SceneTimes = new(SceneTimes)
+		}
+		lists.SceneTimes.SceneTime = append(lists.SceneTimes.SceneTime, &SceneTime{Name: name})
+	})
+	addNames(lists.Extensions.names(), extensions, func(name string) {
+		if lists.Extensions == nil {
+			lists.Extensions = new(Extensions)
+		}
+		lists.Extensions.Extension = append(lists.Extensions.Extension, &Extension{Name: name})
+	})
+	addNames(lists.Transitions.names(), transitions, func(name string) {
+		if lists.Transitions == nil {
+			lists.Transitions = new(Transitions)
+		}
+		lists.Transitions.Transition = append(lists.Transitions.Transition, &Transition{Name: name})
+	})
+}
@@ -0,0 +1,85 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRebuildLists(t *testing.T) {
+	document := scriptDocument(
+		"Transition: Fade in:",
+		"Scene Heading: INT. HOUSE - KITCHEN - DAY",
+		"Character: Ann (V.O.)",
+		"Dialogue: Morning.",
+		"Character: BOB",
+		"Dialogue: Coffee?",
+		"Character: ANN (CONT'D)",
+		"Dialogue: Please.",
+		"Transition: CUT TO:",
+		"Scene Heading: EXT. GARDEN - NIGHT",
+		"Character: Carl (O.S.)",
+		"Dialogue: Hello?",
+	)
+	document.Lists = &Lists{
+		Characters:  &Characters{Character: []*Character{{Name: "Dora"}, {Name: "Bob"}}},
+		Transitions: &Transitions{Transition: []*Transition{{Name: "SMASH CUT TO:"}}},
+	}
+	document.RebuildLists()
+	lists := document.Lists
+	names := func(n int, name func(int) string) string {
+		src := []string{}
+		for i := 0; i < n; i++ {
+			src = append(src, name(i))
+		}
+		return strings.Join(src, ", ")
+	}
+	for _, test := range []struct {
+		list, expected string
+	}{
+		{names(len(lists.Characters.Character), func(i int) string { return lists.Characters.Character[i].Name }), "Dora, Bob, ANN, CARL"},
+		{names(len(lists.Locations.Location), func(i int) string { return lists.Locations.Location[i].Name }), "HOUSE - KITCHEN, GARDEN"},
+		{names(len(lists.SceneIntros.SceneIntro), func(i int) string { return lists.SceneIntros.SceneIntro[i].Name }), "INT., EXT."},
+		{names(len(lists.SceneTimes.SceneTime), func(i int) string { return lists.SceneTimes.SceneTime[i].Name }), "DAY, NIGHT"},
+		{names(len(lists.Extensions.Extension), func(i int) string { return lists.Extensions.Extension[i].Name }), "(V.O.), (O.S.)"},
+		{names(len(lists.Transitions.Transition), func(i int) string { return lists.Transitions.Transition[i].Name }), "SMASH CUT TO:, FADE IN:, CUT TO:"},
+	} {
+		if test.list != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, test.list)
+		}
+	}
+
+	// Rebuilding again doesn't add anything
+	document.RebuildLists()
+	if n := len(lists.Characters.Character); n != 4 {
+		t.Errorf("expected four characters, got %d", n)
+	}
+}
@@ -0,0 +1,267 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// The conflict markers Merge writes around conflicting paragraphs
+const (
+	ConflictStart     = "<<<<<<< OURS"
+	ConflictSeparator = "======="
+	ConflictEnd       = ">>>>>>> THEIRS"
+)
+
+// MergeConflict is a place where both drafts changed the same paragraphs
+// of the base differently
+type MergeConflict struct {
+	// Section is "title page" or "script"
+	Section string `json:"section" yaml:"section"`
+	// Scene is the heading of the scene the conflict is in
+	Scene string `json:"scene,omitempty" yaml:"scene,omitempty"`
+	// Paragraph is the index of the conflict's start marker in the
+	// section's merged paragraphs
+	Paragraph int `json:"paragraph" yaml:"paragraph"`
+	// Base, Ours and Theirs are the text of the conflicting paragraphs
+	Base   []string `json:"base" yaml:"base"`
+	Ours   []string `json:"ours" yaml:"ours"`
+	Theirs []string `json:"theirs" yaml:"theirs"`
+}
+
+// mergeHunk is a run of base paragraphs, from start to end, that a draft
+// replaced with its paragraphs from from to to
+type mergeHunk struct {
+	start, end int
+	from, to   int
+}
+
+// xmlKey returns the XML of v so elements can be compared
+func xmlKey(v interface{}) string {
+	src, err := xml.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%p", v)
+	}
+	return string(src)
+}
+
+// paraKey returns the XML of a paragraph without its page number so
+// paragraphs compare the same after a draft is paginated again
+func paraKey(para *Para) string {
+	key := *para
+	key.PageNumber = ""
+	return xmlKey(&key)
+}
+
+// mergeHunks returns the changes a draft made to the base paragraphs
+func mergeHunks(base, draft []string) []*mergeHunk {
+	hunks := []*mergeHunk{}
+	matches := diffMatches(len(base), len(draft), func(i, j int) bool { return base[i] == draft[j] })
+	i, j := 0, 0
+	for _, match := range append(matches, [2]int{len(base), len(draft)}) {
+		if match[0] > i || match[1] > j {
+			hunks = append(hunks, &mergeHunk{start: i, end: match[0], from: j, to: match[1]})
+		}
+		i, j = match[0]+1, match[1]+1
+	}
+	return hunks
+}
+
+// draftPosition returns the position in a draft of the base position i
+// given the draft's hunks before it
+func draftPosition(hunks []*mergeHunk, i int) int {
+	for _, hunk := range hunks {
+		i += (hunk.to - hunk.from) - (hunk.end - hunk.start)
+	}
+	return i
+}
+
+// conflictMarker returns a paragraph marking a conflict
+func conflictMarker(s string) *Para {
+	return &Para{Style: &Style{BaseStyleName: ActionType}, Text: []*Text{{InnerText: s}}}
+}
+
+// plainTexts returns the text of paragraphs
+func plainTexts(paras []*Para) []string {
+	texts := []string{}
+	for _, para := range paras {
+		texts = append(texts, para.PlainText())
+	}
+	return texts
+}
+
+// mergeParas merges the changes two drafts made to the base paragraphs.
+// Changes to different paragraphs are both kept, as are the same change
+// made by both drafts. Where the drafts changed the same paragraphs
+// differently both versions are kept between conflict markers. Page
+// numbers don't count as a change, the paragraphs neither draft changed
+// are taken from ours.
+func mergeParas(section string, base, ours, theirs []*Para) ([]*Para, []*MergeConflict) {
+	keys := func(paras []*Para) []string {
+		src := []string{}
+		for _, para := range paras {
+			src = append(src, paraKey(para))
+		}
+		return src
+	}
+	baseKeys, ourKeys, theirKeys := keys(base), keys(ours), keys(theirs)
+	ourHunks, theirHunks := mergeHunks(baseKeys, ourKeys), mergeHunks(baseKeys, theirKeys)
+
+	merged, conflicts := []*Para{}, []*MergeConflict{}
+	i, o, t := 0, 0, 0
+	for o < len(ourHunks) || t < len(theirHunks) {
+		// Start a group with the next hunk and add the hunks that
+		// overlap it, two insertions at the same place overlap
+		var start, end int
+		switch {
+		case t >= len(theirHunks) || (o < len(ourHunks) && ourHunks[o].start <= theirHunks[t].start):
+			start, end = ourHunks[o].start, ourHunks[o].end
+		default:
+			start, end = theirHunks[t].start, theirHunks[t].end
+		}
+		overlaps := func(hunk *mergeHunk) bool {
+			return hunk.start < end || hunk.start == start
+		}
+		o0, t0 := o, t
+		for {
+			if o < len(ourHunks) && overlaps(ourHunks[o]) {
+				if ourHunks[o].end > end {
+					end = ourHunks[o].end
+				}
+				o++
+				continue
+			}
+			if t < len(theirHunks) && overlaps(theirHunks[t]) {
+				if theirHunks[t].end > end {
+					end = theirHunks[t].end
+				}
+				t++
+				continue
+			}
+			break
+		}
+		merged = append(merged, ours[draftPosition(ourHunks[:o0], i):draftPosition(ourHunks[:o0], start)]...)
+		ourFrom, ourTo := draftPosition(ourHunks[:o0], start), draftPosition(ourHunks[:o], end)
+		theirFrom, theirTo := draftPosition(theirHunks[:t0], start), draftPosition(theirHunks[:t], end)
+		switch {
+		case t == t0:
+			merged = append(merged, ours[ourFrom:ourTo]...)
+		case o == o0:
+			merged = append(merged, theirs[theirFrom:theirTo]...)
+		case strings.Join(ourKeys[ourFrom:ourTo], "\n") == strings.Join(theirKeys[theirFrom:theirTo], "\n"):
+			merged = append(merged, ours[ourFrom:ourTo]...)
+		default:
+			conflict := &MergeConflict{
+				Section:   section,
+				Paragraph: len(merged),
+				Base:      plainTexts(base[start:end]),
+				Ours:      plainTexts(ours[ourFrom:ourTo]),
+				Theirs:    plainTexts(theirs[theirFrom:theirTo]),
+			}
+			for k := len(merged) - 1; k >= 0 && conflict.Scene == ""; k-- {
+				if merged[k].isSceneHeading() {
+					conflict.Scene = strings.TrimSpace(merged[k].PlainText())
+				}
+			}
+			merged = append(merged, conflictMarker(ConflictStart))
+			merged = append(merged, ours[ourFrom:ourTo]...)
+			merged = append(merged, conflictMarker(ConflictSeparator))
+			merged = append(merged, theirs[theirFrom:theirTo]...)
+			merged = append(merged, conflictMarker(ConflictEnd))
+			conflicts = append(conflicts, conflict)
+		}
+		i = end
+	}
+	merged = append(merged, ours[draftPosition(ourHunks, i):]...)
+	return merged, conflicts
+}
+
+// Merge combines the changes two drafts (ours and theirs) made to a base
+// draft, e.g. two writers working on different scenes. The script and
+// title page are merged paragraph by paragraph, changes to different
+// paragraphs are kept and conflicting changes are written between
+// paragraphs with the conflict markers (ConflictStart, ConflictSeparator
+// and ConflictEnd) and listed in the conflicts returned. The other
+// sections (info, settings, styles, spelling and lists) come from theirs
+// if only they changed it, otherwise from ours.
+func Merge(base, ours, theirs *OpenScreenplay) (*OpenScreenplay, []*MergeConflict, error) {
+	if base == nil || ours == nil || theirs == nil {
+		return nil, nil, fmt.Errorf("three drafts are needed to merge")
+	}
+	merged := *ours
+	unchanged := func(a, b interface{}) bool {
+		return xmlKey(a) == xmlKey(b)
+	}
+	if unchanged(base.Info, ours.Info) {
+		merged.Info = theirs.Info
+	}
+	if unchanged(base.Settings, ours.Settings) {
+		merged.Settings = theirs.Settings
+	}
+	if unchanged(base.Styles, ours.Styles) {
+		merged.Styles = theirs.Styles
+	}
+	if unchanged(base.Spelling, ours.Spelling) {
+		merged.Spelling = theirs.Spelling
+	}
+	if unchanged(base.Lists, ours.Lists) {
+		merged.Lists = theirs.Lists
+	}
+
+	conflicts := []*MergeConflict{}
+	paras := func(document *OpenScreenplay, titlePage bool) []*Para {
+		switch {
+		case titlePage && document.TitlePage != nil:
+			return document.TitlePage.Para
+		case !titlePage && document.Paragraphs != nil:
+			return document.Paragraphs.Para
+		}
+		return nil
+	}
+	titlePage, titleConflicts := mergeParas("title page", paras(base, true), paras(ours, true), paras(theirs, true))
+	if merged.TitlePage != nil || len(titlePage) > 0 {
+		merged.TitlePage = new(TitlePage)
+		if ours.TitlePage != nil {
+			*merged.TitlePage = *ours.TitlePage
+		}
+		merged.TitlePage.Para = titlePage
+	}
+	conflicts = append(conflicts, titleConflicts...)
+	script, scriptConflicts := mergeParas("script", paras(base, false), paras(ours, false), paras(theirs, false))
+	merged.Paragraphs = new(Paragraphs)
+	if ours.Paragraphs != nil {
+		*merged.Paragraphs = *ours.Paragraphs
+	}
+	merged.Paragraphs.Para = script
+	conflicts = append(conflicts, scriptConflicts...)
+	return &merged, conflicts, nil
+}
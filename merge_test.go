@@ -0,0 +1,161 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// scriptLines returns the paragraphs of a document's script as lines of
+// style name and text separated by ": "
+func scriptLines(document *OpenScreenplay) []string {
+	lines := []string{}
+	for _, para := range document.Paragraphs.Para {
+		lines = append(lines, paraStyleName(para)+": "+para.PlainText())
+	}
+	return lines
+}
+
+func TestMerge(t *testing.T) {
+	base := []string{
+		"Scene Heading: INT. KITCHEN - DAY",
+		"Action: Ann makes coffee.",
+		"Scene Heading: EXT. GARDEN - DAY",
+		"Action: Birds sing.",
+		"Character: ANN",
+		"Dialogue: Lovely.",
+		"Scene Heading: INT. OFFICE - NIGHT",
+		"Action: Bob types.",
+	}
+	edit := func(lines []string, i int, line string) []string {
+		lines = append([]string{}, lines...)
+		lines[i] = line
+		return lines
+	}
+
+	// Changes to different scenes are both kept
+	ours := edit(base, 1, "Action: Ann makes tea.")
+	theirs := append(edit(base, 5, "Dialogue: How lovely."), "Scene Heading: EXT. STREET - NIGHT", "Action: Rain.")
+	theirDocument := scriptDocument(theirs...)
+	theirDocument.Settings.SetFlag(SceneNumberingFlag, true)
+	merged, conflicts, err := Merge(scriptDocument(base...), scriptDocument(ours...), theirDocument)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := edit(theirs, 1, "Action: Ann makes tea.")
+	if got := strings.Join(scriptLines(merged), "\n"); len(conflicts) > 0 || got != strings.Join(expected, "\n") {
+		t.Errorf("expected\n%s\ngot %d conflicts and\n%s", strings.Join(expected, "\n"), len(conflicts), got)
+	}
+	if !merged.Settings.Flag(SceneNumberingFlag) {
+		t.Errorf("expected their settings to be kept")
+	}
+
+	// The same change in both drafts is kept once, different changes to
+	// the same paragraph conflict
+	ours = edit(edit(base, 1, "Action: Ann makes tea."), 3, "Action: Birds sing loudly.")
+	theirs = edit(edit(base, 1, "Action: Ann makes tea."), 3, "Action: A dog barks.")
+	merged, conflicts, err = Merge(scriptDocument(base...), scriptDocument(ours...), scriptDocument(theirs...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{
+		"Scene Heading: INT. KITCHEN - DAY",
+		"Action: Ann makes tea.",
+		"Scene Heading: EXT. GARDEN - DAY",
+		"Action: " + ConflictStart,
+		"Action: Birds sing loudly.",
+		"Action: " + ConflictSeparator,
+		"Action: A dog barks.",
+		"Action: " + ConflictEnd,
+		"Character: ANN",
+		"Dialogue: Lovely.",
+		"Scene Heading: INT. OFFICE - NIGHT",
+		"Action: Bob types.",
+	}
+	if got := strings.Join(scriptLines(merged), "\n"); got != strings.Join(expected, "\n") {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(expected, "\n"), got)
+	}
+	if len(conflicts) != 1 {
+		t.Fatalf("expected one conflict, got %d", len(conflicts))
+	}
+	conflict := conflicts[0]
+	if conflict.Section != "script" || conflict.Scene != "EXT. GARDEN - DAY" || conflict.Paragraph != 3 ||
+		strings.Join(conflict.Base, "|") != "Birds sing." || strings.Join(conflict.Ours, "|") != "Birds sing loudly." ||
+		strings.Join(conflict.Theirs, "|") != "A dog barks." {
+		t.Errorf("unexpected conflict %+v", conflict)
+	}
+
+	if _, _, err := Merge(nil, merged, merged); err == nil {
+		t.Errorf("expected an error without a base draft")
+	}
+}
+
+func TestMergePaginated(t *testing.T) {
+	base := []string{}
+	for i := 1; i <= 20; i++ {
+		base = append(base, fmt.Sprintf("Scene Heading: INT. ROOM %d - DAY", i))
+		for j := 1; j <= 5; j++ {
+			base = append(base, fmt.Sprintf("Action: Something happens in room %d, %d.", i, j))
+		}
+	}
+	paginated := func(lines []string) *OpenScreenplay {
+		document := scriptDocument(lines...)
+		document.Paginate()
+		return document
+	}
+
+	// Our draft adds a page of action to the first scene moving every
+	// paragraph after it to a later page, their draft edits the last scene
+	ours := append([]string{}, base[:2]...)
+	for i := 1; i <= 60; i++ {
+		ours = append(ours, fmt.Sprintf("Action: Something new %d.", i))
+	}
+	ours = append(ours, base[2:]...)
+	theirs := append([]string{}, base...)
+	theirs[len(theirs)-1] = "Action: Something else happens."
+	ourDocument := paginated(ours)
+	merged, conflicts, err := Merge(paginated(base), ourDocument, paginated(theirs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := append([]string{}, ours...)
+	expected[len(expected)-1] = "Action: Something else happens."
+	if got := strings.Join(scriptLines(merged), "\n"); len(conflicts) > 0 || got != strings.Join(expected, "\n") {
+		t.Errorf("expected\n%s\ngot %d conflicts and\n%s", strings.Join(expected, "\n"), len(conflicts), got)
+	}
+	for i, para := range merged.Paragraphs.Para[:len(expected)-1] {
+		if para.PageNumber != ourDocument.Paragraphs.Para[i].PageNumber {
+			t.Errorf("expected paragraph %d on our page %s, got %s", i, ourDocument.Paragraphs.Para[i].PageNumber, para.PageNumber)
+			break
+		}
+	}
+}
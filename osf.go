@@ -1,4 +1,4 @@
-// osf is a package for working with Open Screenplay Format 1.2 and 2.0 XML documents.
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
 //
 // @author R. S. Doiel, <rsdoiel@gmail.com>
 //
@@ -30,11 +30,12 @@
 package osf
 
 import (
-	"archive/zip"
 	"bytes"
 	"encoding/xml"
+	"fmt"
 	"io/ioutil"
 	"path"
+	"reflect"
 	"strings"
 )
 
@@ -65,6 +66,13 @@ const (
 	ShotType          = "Shot"
 	SingingType       = "Singing"
 
+	// Structural paragraph types, these hold a screenplay's outline and
+	// set aside material (e.g. Fountain sections and boneyard). They
+	// aren't Fade In styles, AddStructuralStyles defines them in a
+	// document. The package doesn't print them but Fade In does.
+	SectionType  = "Section"
+	BoneyardType = "Boneyard"
+
 	// DynamicLabel types
 	PageNoType      = "Page #"
 	LastRevisedType = "Last Revised"
@@ -80,230 +88,500 @@ var (
 	MaxLineWidth = 80
 )
 
-// OpenScreenplay holds the root structure for Unmarshaling OSF 1.2 and 2.0
+// OpenScreenplay holds the root structure for Unmarshaling OSF 1.2, 2.0 and 2.1
 type OpenScreenplay struct {
-	XMLName    xml.Name    `xml:"document" json:"-" yaml:"-"`
-	Type       string      `xml:"type,attr" json:"document_type" yaml:"document_type"`
-	Version    string      `xml:"version,attr" json:"version" yaml:"version"`
-	Info       *Info       `xml:"info" json:"info,omitempty" yaml:"info,omitempty"`
-	Settings   *Settings   `xml:"settings" json:"settings" yaml:"settings"`
-	Styles     *Styles     `xml:"styles,omitempty" json:"styles,omitempty" yaml:"styles,omitempty"`
-	Paragraphs *Paragraphs `xml:"paragraphs" json:"paragraphs" yaml:"paragraphs"`
-	Spelling   *Spelling   `xml:"spelling,omitempty" json:"spelling,omitempty" yaml:"spelling,omitempty"`
-	Lists      *Lists      `xml:"lists" json:"lists,omitempty" yaml:"lists,omitempty"`
-	TitlePage  *TitlePage  `xml:"titlepage,omitempty" json:"title_page,omitempty" yaml:"title_page,omitempty"`
+	XMLName         xml.Name      `xml:"document" json:"-" yaml:"-"`
+	Type            string        `xml:"type,attr" json:"document_type" yaml:"document_type"`
+	Version         string        `xml:"version,attr" json:"version" yaml:"version"`
+	Info            *Info         `xml:"info" json:"info,omitempty" yaml:"info,omitempty"`
+	Settings        *Settings     `xml:"settings" json:"settings" yaml:"settings"`
+	Styles          *Styles       `xml:"styles,omitempty" json:"styles,omitempty" yaml:"styles,omitempty"`
+	Paragraphs      *Paragraphs   `xml:"paragraphs" json:"paragraphs" yaml:"paragraphs"`
+	Spelling        *Spelling     `xml:"spelling,omitempty" json:"spelling,omitempty" yaml:"spelling,omitempty"`
+	Lists           *Lists        `xml:"lists" json:"lists,omitempty" yaml:"lists,omitempty"`
+	TitlePage       *TitlePage    `xml:"titlepage,omitempty" json:"title_page,omitempty" yaml:"title_page,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
+
+	// elementOrder records the order of child elements as they were read
+	// so MarshalXML can write them back out in the same order.
+	elementOrder []string
 }
 
 type Info struct {
-	XMLName     xml.Name `xml:"info" json:"-" yaml:"-"`
-	UUID        string   `xml:"uuid,attr,omitempty" json:"uuid,omitempty" yaml:"uuid,omitempty"`
-	Title       string   `xml:"title,attr,omitempty" json:"title,omitempty" yaml:"title,omitempty"`
-	TitleFormat string   `xml:"title_format,attr,omitempty" json:"title_format,omitempty" yaml:"title_format,omitempty"`
-	WrittenBy   string   `xml:"written_by,attr,omitempty" json:"written_by,omitempty" yaml:"written_by,omitempty"`
-	Copyright   string   `xml:"copyright,attr,omitempty" json:"copyright,omitempty" yaml:"copyright,omitempty"`
-	Contact     string   `xml:"contact,attr,omitempty" json:"contact,omitempty" yaml:"contact,omitempty"`
-	Drafts      string   `xml:"drafts,attr,omitempty" json:"drafts,omitempty" yaml:"drafts,omitempty"`
-	PageCount   string   `xml:"pagecount,attr,omitempty" json:"page_count,omitempty" yaml:"page_count,omitempty"`
-}
-
+	XMLName         xml.Name      `xml:"info" json:"-" yaml:"-"`
+	UUID            string        `xml:"uuid,attr,omitempty" json:"uuid,omitempty" yaml:"uuid,omitempty"`
+	Title           string        `xml:"title,attr,omitempty" json:"title,omitempty" yaml:"title,omitempty"`
+	TitleFormat     string        `xml:"title_format,attr,omitempty" json:"title_format,omitempty" yaml:"title_format,omitempty"`
+	WrittenBy       string        `xml:"written_by,attr,omitempty" json:"written_by,omitempty" yaml:"written_by,omitempty"`
+	Copyright       string        `xml:"copyright,attr,omitempty" json:"copyright,omitempty" yaml:"copyright,omitempty"`
+	Contact         string        `xml:"contact,attr,omitempty" json:"contact,omitempty" yaml:"contact,omitempty"`
+	Drafts          string        `xml:"drafts,attr,omitempty" json:"drafts,omitempty" yaml:"drafts,omitempty"`
+	PageCount       string        `xml:"pagecount,attr,omitempty" json:"page_count,omitempty" yaml:"page_count,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
+}
+
+// Settings holds the page layout, numbering and revision settings of a
+// document. Attributes are kept as strings, see settings.go for typed accessors.
 type Settings struct {
-	XMLName            xml.Name `xml:"settings" json:"-" yaml:"-"`
-	PageWidth          string   `xml:"page_width,attr,omitempty" json:"page_width,omitempty" yaml:"page_width,omitempty"`
-	PageHeight         string   `xml:"page_height,attr,omitempty" json:"page_height,omitempty" yaml:"page_height,omitempty"`
-	MarginTop          string   `xml:"margin_top,attr,omitempty" json:"margin_top,omitempty" yaml:"margin_top,omitempty"`
-	MarginBottom       string   `xml:"margin_bottom,attr,omitempty" json:"margin_bottom,omitempty" yaml:"margin_bottom,omitempty"`
-	MarginLeft         string   `xml:"margin_left,attr,omitempty" json:"margin_left,omitempty" yaml:"margin_left,omitempty"`
-	MarginRight        string   `xml:"margin_right,attr,omitempty" json:"margin_right,omitempty" yaml:"margin_right,omitempty"`
-	NormalLinesPerInch string   `xml:"normal_linesperinch,attr,omitempty" json:"normal_lines_per_inch,omitempty" yaml:"normal_lines_per_inch,omitempty"`
-	DialogueContinues  string   `xml:"dialogue_continues,attr,omitempty" json:"dialog_continues,omitempty" yaml:"dialog_continues,omitempty"`
-	ContText           string   `xml:"cont_text,attr,omitempty" json:"cont_text,omitempty" yaml:"cont_text,omitempty"`
-	MoreText           string   `xml:"more_text,attr,omitempty" json:"more_text,omitempty" yaml:"more_text,omitempty"`
-	ContinuedText      string   `xml:"continued_text,attr,omitempty" json:"continued_text,omitempty" yaml:"continued_text,omitempty"`
-	OmittedText        string   `xml:"omitted_text,attr,omitempty" json:"omitted_text,omitempty" yaml:"omitted_text,omitempty"`
-	PageNumberFormat   string   `xml:"pagenumber_format,attr,omitempty" json:"page_number_format,omitempty" yaml:"page_number_format,omitempty"`
-	PageNumberStart    string   `xml:"pagenumber_start,attr,omitempty" json:"page_number_start,omitempty" yaml:"page_number_start,omitempty"`
-	PageNumberFirst    string   `xml:"pagenumber_first,attr,omitempty" json:"page_number_first,omitempty" yaml:"page_number_first,omitempty"`
-	Revision           string   `xml:"revision,attr,omitempty" json:"revision,omitempty" yaml:"revision,omitempty"`
-	ShowRevisions      string   `xml:"show_revisions,attr,omitempty" json:"show_revisions,omitempty" yaml:"show_reviserions,omitempty"`
-	SceneNumbering     string   `xml:"scene_numbering,attr,omitempty" json:"scene_numbering,omitempty" yaml:"scene_numbering,omitempty"`
-	ScenesLocked       string   `xml:"scenes_locked,attr,omitempty" json:"scenes_locked,omitempty" yaml:"scenes_locked,omitempty"`
-	PageNumbering      string   `xml:"page_numbering,attr,omitempty" json:"page_numbering,omitempty" yaml:"page_numbering,omitempty"`
-	PagesLocked        string   `xml:"pages_locked,attr,omitempty" json:"pages_locked,omitempty" yaml:"pages_locked,omitempty"`
+	XMLName              xml.Name      `xml:"settings" json:"-" yaml:"-"`
+	PageWidth            string        `xml:"page_width,attr,omitempty" json:"page_width,omitempty" yaml:"page_width,omitempty"`
+	PageHeight           string        `xml:"page_height,attr,omitempty" json:"page_height,omitempty" yaml:"page_height,omitempty"`
+	MarginTop            string        `xml:"margin_top,attr,omitempty" json:"margin_top,omitempty" yaml:"margin_top,omitempty"`
+	MarginBottom         string        `xml:"margin_bottom,attr,omitempty" json:"margin_bottom,omitempty" yaml:"margin_bottom,omitempty"`
+	MarginLeft           string        `xml:"margin_left,attr,omitempty" json:"margin_left,omitempty" yaml:"margin_left,omitempty"`
+	MarginRight          string        `xml:"margin_right,attr,omitempty" json:"margin_right,omitempty" yaml:"margin_right,omitempty"`
+	NormalLinesPerInch   string        `xml:"normal_linesperinch,attr,omitempty" json:"normal_lines_per_inch,omitempty" yaml:"normal_lines_per_inch,omitempty"`
+	DialogueContinues    string        `xml:"dialogue_continues,attr,omitempty" json:"dialog_continues,omitempty" yaml:"dialog_continues,omitempty"`
+	ContText             string        `xml:"cont_text,attr,omitempty" json:"cont_text,omitempty" yaml:"cont_text,omitempty"`
+	MoreText             string        `xml:"more_text,attr,omitempty" json:"more_text,omitempty" yaml:"more_text,omitempty"`
+	ContinuedText        string        `xml:"continued_text,attr,omitempty" json:"continued_text,omitempty" yaml:"continued_text,omitempty"`
+	OmittedText          string        `xml:"omitted_text,attr,omitempty" json:"omitted_text,omitempty" yaml:"omitted_text,omitempty"`
+	PageNumberFormat     string        `xml:"pagenumber_format,attr,omitempty" json:"page_number_format,omitempty" yaml:"page_number_format,omitempty"`
+	PageNumberStart      string        `xml:"pagenumber_start,attr,omitempty" json:"page_number_start,omitempty" yaml:"page_number_start,omitempty"`
+	PageNumberFirst      string        `xml:"pagenumber_first,attr,omitempty" json:"page_number_first,omitempty" yaml:"page_number_first,omitempty"`
+	Revision             string        `xml:"revision,attr,omitempty" json:"revision,omitempty" yaml:"revision,omitempty"`
+	ShowRevisions        string        `xml:"show_revisions,attr,omitempty" json:"show_revisions,omitempty" yaml:"show_reviserions,omitempty"`
+	SceneNumbering       string        `xml:"scene_numbering,attr,omitempty" json:"scene_numbering,omitempty" yaml:"scene_numbering,omitempty"`
+	ScenesLocked         string        `xml:"scenes_locked,attr,omitempty" json:"scenes_locked,omitempty" yaml:"scenes_locked,omitempty"`
+	PageNumbering        string        `xml:"page_numbering,attr,omitempty" json:"page_numbering,omitempty" yaml:"page_numbering,omitempty"`
+	PagesLocked          string        `xml:"pages_locked,attr,omitempty" json:"pages_locked,omitempty" yaml:"pages_locked,omitempty"`
+	ElementSpacing       string        `xml:"element_spacing,attr,omitempty" json:"element_spacing,omitempty" yaml:"element_spacing,omitempty"`
+	BreakOnSentences     string        `xml:"break_on_sentences,attr,omitempty" json:"break_on_sentences,omitempty" yaml:"break_on_sentences,omitempty"`
+	DialoguePageBreaks   string        `xml:"dialogue_pagebreaks,attr,omitempty" json:"dialogue_page_breaks,omitempty" yaml:"dialogue_page_breaks,omitempty"`
+	ScenesContinue       string        `xml:"scenes_continue,attr,omitempty" json:"scenes_continue,omitempty" yaml:"scenes_continue,omitempty"`
+	NumberContinued      string        `xml:"number_continued,attr,omitempty" json:"number_continued,omitempty" yaml:"number_continued,omitempty"`
+	AutoOmitScenes       string        `xml:"auto_omit_scenes,attr,omitempty" json:"auto_omit_scenes,omitempty" yaml:"auto_omit_scenes,omitempty"`
+	SceneTimeSeparator   string        `xml:"scene_time_separator,attr,omitempty" json:"scene_time_separator,omitempty" yaml:"scene_time_separator,omitempty"`
+	PageHeader           string        `xml:"page_header,attr,omitempty" json:"page_header,omitempty" yaml:"page_header,omitempty"`
+	PageFooter           string        `xml:"page_footer,attr,omitempty" json:"page_footer,omitempty" yaml:"page_footer,omitempty"`
+	HeaderAlignment      string        `xml:"header_alignment,attr,omitempty" json:"header_alignment,omitempty" yaml:"header_alignment,omitempty"`
+	FooterAlignment      string        `xml:"footer_alignment,attr,omitempty" json:"footer_alignment,omitempty" yaml:"footer_alignment,omitempty"`
+	HeaderFirstPage      string        `xml:"header_first_page,attr,omitempty" json:"header_first_page,omitempty" yaml:"header_first_page,omitempty"`
+	FooterFirstPage      string        `xml:"footer_first_page,attr,omitempty" json:"footer_first_page,omitempty" yaml:"footer_first_page,omitempty"`
+	PageNumberMode       string        `xml:"pagenumber_mode,attr,omitempty" json:"page_number_mode,omitempty" yaml:"page_number_mode,omitempty"`
+	SceneNumberMode      string        `xml:"scenenumber_mode,attr,omitempty" json:"scene_number_mode,omitempty" yaml:"scene_number_mode,omitempty"`
+	SceneNumberSkipIO    string        `xml:"scenenumber_skip_io,attr,omitempty" json:"scene_number_skip_io,omitempty" yaml:"scene_number_skip_io,omitempty"`
+	SceneNumberStart     string        `xml:"scenenumber_start,attr,omitempty" json:"scene_number_start,omitempty" yaml:"scene_number_start,omitempty"`
+	SceneNumberFormat    string        `xml:"scenenumber_format,attr,omitempty" json:"scene_number_format,omitempty" yaml:"scene_number_format,omitempty"`
+	SceneNumberPosition  string        `xml:"scenenumber_position,attr,omitempty" json:"scene_number_position,omitempty" yaml:"scene_number_position,omitempty"`
+	DialogueNumbering    string        `xml:"dialogue_numbering,attr,omitempty" json:"dialogue_numbering,omitempty" yaml:"dialogue_numbering,omitempty"`
+	DialogueNumberStart  string        `xml:"dialoguenumber_start,attr,omitempty" json:"dialogue_number_start,omitempty" yaml:"dialogue_number_start,omitempty"`
+	DialogueNumberFormat string        `xml:"dialoguenumber_format,attr,omitempty" json:"dialogue_number_format,omitempty" yaml:"dialogue_number_format,omitempty"`
+	DialogueLocked       string        `xml:"dialogue_locked,attr,omitempty" json:"dialogue_locked,omitempty" yaml:"dialogue_locked,omitempty"`
+	ShowAllRevisions     string        `xml:"show_all_revisions,attr,omitempty" json:"show_all_revisions,omitempty" yaml:"show_all_revisions,omitempty"`
+	UnknownAttrs         []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements      []*AnyElement `xml:",any" json:"-" yaml:"-"`
 }
 
 type Styles struct {
-	XMLName xml.Name `xml:"styles" json:"styles" yaml:"styles"`
-	Style   []*Style
+	XMLName         xml.Name      `xml:"styles" json:"styles" yaml:"styles"`
+	Style           []*Style      `xml:"style,omitempty" json:"style,omitempty" yaml:"style,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
+
+	elementOrder []string
 }
 
 type Style struct {
-	XMLName       xml.Name `xml:"style" json:"-" yaml:"-"`
-	Name          string   `xml:"name,attr,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
-	Builtin       string   `xml:"builtin,attr,omitempty" json:"builtin,omitempty" yaml:"builtin,omitempty"`
-	BuiltinIndex  string   `xml:"builtin_index,attr,omitempty" json:"builtin_index,omitempty" yaml:"builtin_index,omitempty"`
-	Label         string   `xml:"label,attr,omitempty" json:"label,omitempty" yaml:"label,omitempty"`
-	BaseStyleName string   `xml:"basestylename,attr,omitempty" json:"basestylename,omitempty" yaml:"basestylename,omitempty"`
-	StyleEnter    string   `xml:"style_enter,attr,omitempty" json:"style_enter,omitempty" yaml:"style_enter,omitempty"`
-	Font          string   `xml:"font,attr,omitempty" json:"font,omitempty" yaml:"font,omitempty"`
-	Size          string   `xml:"size,attr,omitempty" json:"size,omitempty" yaml:"size,omitempty"`
-	SpaceBefore   string   `xml:"spacebefore,attr,omitempty" json:"spacebefore,omitempty" yaml:"spacebefore,omitempty"`
-	StyleTab      string   `xml:"style_tab,attr,omitempty" json:"style_tab,omitempty" yaml:"style_tab,omitempty"`
-	KeepWithNext  string   `xml:"keepwithnext,attr,omitempty" json:"keepwithnext,omitempty" yaml:"keepwithnext,omitempty"`
-	Effects       string   `xml:"effects,attr,omitempty" json:"effects,omitempty" yaml:"effects,omitempty"`
-	LeftIdent     string   `xml:"leftindent,attr,omitempty" json:"leftindent,omitempty" yaml:"leftindent,omitempty"`
-	RightIdent    string   `xml:"rightindent,attr,omitempty" json:"rightindent,omitempty" yaml:"rightindent,omitempty"`
-	Align         string   `xml:"align,attr,omitempty" json:"align,omitempty" yaml:"align,omitempty"`
+	XMLName         xml.Name      `xml:"style" json:"-" yaml:"-"`
+	Name            string        `xml:"name,attr,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	Builtin         string        `xml:"builtin,attr,omitempty" json:"builtin,omitempty" yaml:"builtin,omitempty"`
+	BuiltinIndex    string        `xml:"builtin_index,attr,omitempty" json:"builtin_index,omitempty" yaml:"builtin_index,omitempty"`
+	Label           string        `xml:"label,attr,omitempty" json:"label,omitempty" yaml:"label,omitempty"`
+	BaseStyleName   string        `xml:"basestylename,attr,omitempty" json:"basestylename,omitempty" yaml:"basestylename,omitempty"`
+	StyleEnter      string        `xml:"style_enter,attr,omitempty" json:"style_enter,omitempty" yaml:"style_enter,omitempty"`
+	StyleTabBefore  string        `xml:"style_tab_before,attr,omitempty" json:"style_tab_before,omitempty" yaml:"style_tab_before,omitempty"`
+	StyleTabAfter   string        `xml:"style_tab_after,attr,omitempty" json:"style_tab_after,omitempty" yaml:"style_tab_after,omitempty"`
+	Font            string        `xml:"font,attr,omitempty" json:"font,omitempty" yaml:"font,omitempty"`
+	Size            string        `xml:"size,attr,omitempty" json:"size,omitempty" yaml:"size,omitempty"`
+	SpaceBefore     string        `xml:"spacebefore,attr,omitempty" json:"spacebefore,omitempty" yaml:"spacebefore,omitempty"`
+	StyleTab        string        `xml:"style_tab,attr,omitempty" json:"style_tab,omitempty" yaml:"style_tab,omitempty"`
+	KeepWithNext    string        `xml:"keepwithnext,attr,omitempty" json:"keepwithnext,omitempty" yaml:"keepwithnext,omitempty"`
+	Effects         string        `xml:"effects,attr,omitempty" json:"effects,omitempty" yaml:"effects,omitempty"`
+	LeftIdent       string        `xml:"leftindent,attr,omitempty" json:"leftindent,omitempty" yaml:"leftindent,omitempty"`
+	RightIdent      string        `xml:"rightindent,attr,omitempty" json:"rightindent,omitempty" yaml:"rightindent,omitempty"`
+	Align           string        `xml:"align,attr,omitempty" json:"align,omitempty" yaml:"align,omitempty"`
+	Bold            string        `xml:"bold,attr,omitempty" json:"bold,omitempty" yaml:"bold,omitempty"`
+	Italic          string        `xml:"italic,attr,omitempty" json:"italic,omitempty" yaml:"italic,omitempty"`
+	Underline       string        `xml:"underline,attr,omitempty" json:"underline,omitempty" yaml:"underline,omitempty"`
+	Color           string        `xml:"color,attr,omitempty" json:"color,omitempty" yaml:"color,omitempty"`
+	AllCaps         string        `xml:"allcaps,attr,omitempty" json:"allcaps,omitempty" yaml:"allcaps,omitempty"`
+	LineSpacing     string        `xml:"linespacing,attr,omitempty" json:"linespacing,omitempty" yaml:"linespacing,omitempty"`
+	PageBreakBefore string        `xml:"pagebreakbefore,attr,omitempty" json:"pagebreakbefore,omitempty" yaml:"pagebreakbefore,omitempty"`
+	DualDialogue    string        `xml:"dualdialogue,attr,omitempty" json:"dualdialogue,omitempty" yaml:"dualdialogue,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
 }
 
 type Paragraphs struct {
-	XMLName xml.Name `xml:"paragraphs" json:"paragraphs" yaml:"paragraphs"`
-	Para    []*Para  `xml:"para,omitempty" json:"para,omitempty" yaml:"para,omitempty"`
+	XMLName         xml.Name      `xml:"paragraphs" json:"paragraphs" yaml:"paragraphs"`
+	Para            []*Para       `xml:"para,omitempty" json:"para,omitempty" yaml:"para,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
+
+	elementOrder []string
 }
 
 type Para struct {
-	XMLName    xml.Name `xml:"para" json:"-" yaml:"-"`
-	PageNumber string   `xml:"page_number,attr,omitempty" json:"page_number,omitempty" yaml:"page_number,omitempty"`
-	Bookmark   string   `xml:"bookmark,attr,omitempty" json:"bookmark,omitempty" yaml:"bookmark,omitempty"`
-	Style      *Style   `xml:"style,omitempty" json:"style,omitempty" yaml:"style,omitempty"`
-	Text       []*Text  `xml:"text,omitempty" json:"text,omitempty" yaml:"text,omitempty"`
-	Marks      *Marks   `xml:"marks,omitempty" json:"marks,omitempty" yaml:"marks,omitempty"`
+	XMLName         xml.Name      `xml:"para" json:"-" yaml:"-"`
+	SceneNumber     string        `xml:"scene_number,attr,omitempty" json:"scene_number,omitempty" yaml:"scene_number,omitempty"`
+	PageNumber      string        `xml:"page_number,attr,omitempty" json:"page_number,omitempty" yaml:"page_number,omitempty"`
+	Bookmark        string        `xml:"bookmark,attr,omitempty" json:"bookmark,omitempty" yaml:"bookmark,omitempty"`
+	Note            string        `xml:"note,attr,omitempty" json:"note,omitempty" yaml:"note,omitempty"`
+	Synopsis        string        `xml:"synopsis,attr,omitempty" json:"synopsis,omitempty" yaml:"synopsis,omitempty"`
+	SynopsisColor   string        `xml:"synopsis_color,attr,omitempty" json:"synopsis_color,omitempty" yaml:"synopsis_color,omitempty"`
+	Level           string        `xml:"level,attr,omitempty" json:"level,omitempty" yaml:"level,omitempty"`
+	Style           *Style        `xml:"style,omitempty" json:"style,omitempty" yaml:"style,omitempty"`
+	Text            []*Text       `xml:"text,omitempty" json:"text,omitempty" yaml:"text,omitempty"`
+	Marks           *Marks        `xml:"marks,omitempty" json:"marks,omitempty" yaml:"marks,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
+
+	elementOrder []string
 }
 
 type Text struct {
-	XMLName       xml.Name `xml:"text" json:"-" yaml:"-"`
-	Underline     string   `xml:"underline,attr,omitempty" json:"underline,omitempty" yaml:"underline,omitempty"`
-	Italic        string   `xml:"italic,attr,omitempty" json:"italic,omitempty" yaml:"italic,omitempty"`
-	Bold          string   `xml:"bold,attr,omitempty" json:"bold,omitempty" yaml:"bold,omitempty"`
-	Strikethrough string   `xml:"strikethrough,attr,omitempty" json:"strikethrough,omitempty" yaml:"strikethrough,omitempty"`
-	AllCaps       string   `xml:"allcaps,attr,omitempty" json:"allcaps,omitempty" yaml:"allcaps,omitempty"`
-	InnerText     string   `xml:",chardata" json:"inner_text" yaml:"inner_text"`
+	XMLName         xml.Name      `xml:"text" json:"-" yaml:"-"`
+	Underline       string        `xml:"underline,attr,omitempty" json:"underline,omitempty" yaml:"underline,omitempty"`
+	Italic          string        `xml:"italic,attr,omitempty" json:"italic,omitempty" yaml:"italic,omitempty"`
+	Bold            string        `xml:"bold,attr,omitempty" json:"bold,omitempty" yaml:"bold,omitempty"`
+	Strikethrough   string        `xml:"strikethrough,attr,omitempty" json:"strikethrough,omitempty" yaml:"strikethrough,omitempty"`
+	AllCaps         string        `xml:"allcaps,attr,omitempty" json:"allcaps,omitempty" yaml:"allcaps,omitempty"`
+	Revision        string        `xml:"revision,attr,omitempty" json:"revision,omitempty" yaml:"revision,omitempty"`
+	InnerText       string        `xml:",chardata" json:"inner_text" yaml:"inner_text"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
 }
 
 type Marks struct {
-	XMLName xml.Name `xml:"marks" json:"marks" yaml:"marks"`
-	Mark    []*Mark  `xml:"mark,omitempty" json:"mark,omitempty" yaml:"mark,omitempty"`
+	XMLName         xml.Name      `xml:"marks" json:"marks" yaml:"marks"`
+	Mark            []*Mark       `xml:"mark,omitempty" json:"mark,omitempty" yaml:"mark,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
+
+	elementOrder []string
 }
 
 type Mark struct {
-	XMLName  xml.Name `xml:"mark" json:"-" yaml:"-"`
-	At       string   `xml:"at,attr,omitempty" json:"at,omitempty" yaml:"at,omitempty"`
-	Revision string   `xml:"revision,attr,omitempty" json:"revision,omitempty" yaml:"revision,omitempty"`
+	XMLName         xml.Name      `xml:"mark" json:"-" yaml:"-"`
+	At              string        `xml:"at,attr,omitempty" json:"at,omitempty" yaml:"at,omitempty"`
+	Revision        string        `xml:"revision,attr,omitempty" json:"revision,omitempty" yaml:"revision,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
 }
 
 type Spelling struct {
-	XMLName        xml.Name        `xml:"spelling" json:"-" yaml:"-"`
-	Language       string          `xml:"language,attr,omitempty" json:"language,omitempty" yaml:"language,omitempty"`
-	UserDictionary *UserDictionary `xml:"user_dictionary,omitempty" json:"user_dictionary,omitempty" yaml:"user_dictionary,omitempty"`
+	XMLName         xml.Name        `xml:"spelling" json:"-" yaml:"-"`
+	Language        string          `xml:"language,attr,omitempty" json:"language,omitempty" yaml:"language,omitempty"`
+	UserDictionary  *UserDictionary `xml:"user_dictionary,omitempty" json:"user_dictionary,omitempty" yaml:"user_dictionary,omitempty"`
+	UnknownAttrs    []xml.Attr      `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement   `xml:",any" json:"-" yaml:"-"`
+
+	elementOrder []string
 }
 
 type UserDictionary struct {
-	XMLName xml.Name `xml:"user_dictionary" json:"-" yaml:"-"`
-	Entry   []*Entry `xml:"entry,omitempty" json:"entry,omitempty" yaml:"entry,omitempty"`
+	XMLName         xml.Name      `xml:"user_dictionary" json:"-" yaml:"-"`
+	Entry           []*Entry      `xml:"entry,omitempty" json:"entry,omitempty" yaml:"entry,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
+
+	elementOrder []string
 }
 
 type Entry struct {
-	XMLName xml.Name `xml:"entry" json:"-" yaml:"-"`
-	Word    string   `xml:"work,attr,omitempty" json:"word,omitempty" yaml:"word,omitempty"`
+	XMLName         xml.Name      `xml:"entry" json:"-" yaml:"-"`
+	Word            string        `xml:"word,attr,omitempty" json:"word,omitempty" yaml:"word,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
 }
 
 type Lists struct {
-	XMLName        xml.Name        `xml:"lists" json:"lists" yaml:"lists"`
-	Characters     *Characters     `xml:"characters,omitempty" json:"characters,omitempty" yaml:"characters,omitempty"`
-	Locations      *Locations      `xml:"locations,omitempty" json:"locations,omitempty" yaml:"locations,omitempty"`
-	SceneIntros    *SceneIntros    `xml:"scene_intros,omitempty" json:"scene_intros,omitempty" yaml:"scene_intros,omitempty"`
-	SceneTimes     *SceneTimes     `xml:"scene_times,omitempty" json:"scene_times,omitempty" yaml:"scene_times,omitempty"`
-	Extensions     *Extensions     `xml:"extensions,omitempty" json:"extensions,omitempty" yaml:"extensions,omitempty"`
-	Transitions    *Transitions    `xml:"transitions,omitempty" json:"transitions,omitempty" yaml:"transitions,omitempty"`
-	RevisionColors *RevisionColors `xml:"revision_colors,omitempty" json:"revision_colors,omitempty" yaml:"revision_colors,omitempty"`
-	TagCategories  *TagCategories  `xml:"tag_categories,omitempty" json:"tag_categories,omitempty" yaml:"tag_categories,omitempty"`
+	XMLName         xml.Name        `xml:"lists" json:"lists" yaml:"lists"`
+	Characters      *Characters     `xml:"characters,omitempty" json:"characters,omitempty" yaml:"characters,omitempty"`
+	Locations       *Locations      `xml:"locations,omitempty" json:"locations,omitempty" yaml:"locations,omitempty"`
+	SceneIntros     *SceneIntros    `xml:"scene_intros,omitempty" json:"scene_intros,omitempty" yaml:"scene_intros,omitempty"`
+	SceneTimes      *SceneTimes     `xml:"scene_times,omitempty" json:"scene_times,omitempty" yaml:"scene_times,omitempty"`
+	Extensions      *Extensions     `xml:"extensions,omitempty" json:"extensions,omitempty" yaml:"extensions,omitempty"`
+	Transitions     *Transitions    `xml:"transitions,omitempty" json:"transitions,omitempty" yaml:"transitions,omitempty"`
+	RevisionColors  *RevisionColors `xml:"revision_colors,omitempty" json:"revision_colors,omitempty" yaml:"revision_colors,omitempty"`
+	TagCategories   *TagCategories  `xml:"tag_categories,omitempty" json:"tag_categories,omitempty" yaml:"tag_categories,omitempty"`
+	UnknownAttrs    []xml.Attr      `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement   `xml:",any" json:"-" yaml:"-"`
+
+	elementOrder []string
 }
 
 type Characters struct {
-	XMLName   xml.Name     `xml:"characters" json:"characters" yaml:"characters"`
-	Character []*Character `xml:"character,omitempty" json:"character,omitempty" yaml:"character,omitempty"`
+	XMLName         xml.Name      `xml:"characters" json:"characters" yaml:"characters"`
+	Character       []*Character  `xml:"character,omitempty" json:"character,omitempty" yaml:"character,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
+
+	elementOrder []string
 }
 
 type Character struct {
-	XMLName xml.Name `xml:"character" json:"-" yaml:"-"`
-	Name    string   `xml:"name,attr,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	XMLName         xml.Name      `xml:"character" json:"-" yaml:"-"`
+	Name            string        `xml:"name,attr,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
 }
 
 type Locations struct {
-	XMLName  xml.Name    `xml:"locations" json:"locations" yaml:"locations"`
-	Location []*Location `xml:"location,omitempty" json:"location,omitempty" yaml:"location,omitempty"`
+	XMLName         xml.Name      `xml:"locations" json:"locations" yaml:"locations"`
+	Location        []*Location   `xml:"location,omitempty" json:"location,omitempty" yaml:"location,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
+
+	elementOrder []string
 }
 
 type Location struct {
-	XMLName xml.Name `xml:"location" json:"-" yaml:"-"`
-	Name    string   `xml:"name,attr,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	XMLName         xml.Name      `xml:"location" json:"-" yaml:"-"`
+	Name            string        `xml:"name,attr,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
 }
 
 type SceneIntros struct {
-	XMLName    xml.Name      `xml:"scene_intros" json:"scene_intros" yaml:"scene_intros,omitempty"`
-	SceneIntro []*SceneIntro `xml:"scene_intro,omitempty" json:"scene_intro,omitempty" yaml:"screne_intro"`
+	XMLName         xml.Name      `xml:"scene_intros" json:"scene_intros" yaml:"scene_intros,omitempty"`
+	SceneIntro      []*SceneIntro `xml:"scene_intro,omitempty" json:"scene_intro,omitempty" yaml:"screne_intro"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
+
+	elementOrder []string
 }
 
 type SceneIntro struct {
-	XMLName xml.Name `xml:"scene_intro" json:"-" yaml:"-"`
-	Name    string   `xml:"name,attr,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	XMLName         xml.Name      `xml:"scene_intro" json:"-" yaml:"-"`
+	Name            string        `xml:"name,attr,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
 }
 
 type SceneTimes struct {
-	XMLName   xml.Name     `xml:"scene_times" json:"scene_times" yaml:"scene_times"`
-	SceneTime []*SceneTime `xml:"scene_time,omitempty" json:"scene_time,omitempty" yaml:"scene_time,omitempty"`
+	XMLName         xml.Name      `xml:"scene_times" json:"scene_times" yaml:"scene_times"`
+	SceneTime       []*SceneTime  `xml:"scene_time,omitempty" json:"scene_time,omitempty" yaml:"scene_time,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
+
+	elementOrder []string
 }
 
 type SceneTime struct {
-	XMLName xml.Name `xml:"scene_time" json:"-" yaml:"-"`
-	Name    string   `xml:"name,attr,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	XMLName         xml.Name      `xml:"scene_time" json:"-" yaml:"-"`
+	Name            string        `xml:"name,attr,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
 }
 
 type Extensions struct {
-	XMLName   xml.Name     `xml:"extensions" json:"extentions" yaml:"extentions"`
-	Extension []*Extension `xml:"extension,omitempty" json:"extension,omitempty" yaml:"extension,omitempty"`
+	XMLName         xml.Name      `xml:"extensions" json:"extentions" yaml:"extentions"`
+	Extension       []*Extension  `xml:"extension,omitempty" json:"extension,omitempty" yaml:"extension,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
+
+	elementOrder []string
 }
 
 type Extension struct {
-	XMLName xml.Name `xml:"extension" json:"-" yaml:"-"`
-	Name    string   `xml:"name,attr,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	XMLName         xml.Name      `xml:"extension" json:"-" yaml:"-"`
+	Name            string        `xml:"name,attr,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
 }
 
 type Transitions struct {
-	XMLName    xml.Name      `xml:"transitions" json:"transitions" yaml:"transitions"`
-	Transition []*Transition `xml:"transition,omitempty" json:"transition,omitempty" yaml:"transition,omitempty"`
+	XMLName         xml.Name      `xml:"transitions" json:"transitions" yaml:"transitions"`
+	Transition      []*Transition `xml:"transition,omitempty" json:"transition,omitempty" yaml:"transition,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
+
+	elementOrder []string
 }
 
 type Transition struct {
-	XMLName xml.Name `xml:"transition" json:"-" yaml:"-"`
-	Name    string   `xml:"name,attr,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	XMLName         xml.Name      `xml:"transition" json:"-" yaml:"-"`
+	Name            string        `xml:"name,attr,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
 }
 
 type RevisionColors struct {
-	XMLName       xml.Name         `xml:"revision_colors" json:"revision_colors" yaml:"revision_colors,omitempty"`
-	RevisionColor []*RevisionColor `xml:"revision_color,omitempty" json:"revision_color,omitempty" yaml:"revision_color,omitempty"`
+	XMLName         xml.Name         `xml:"revision_colors" json:"revision_colors" yaml:"revision_colors,omitempty"`
+	RevisionColor   []*RevisionColor `xml:"revision_color,omitempty" json:"revision_color,omitempty" yaml:"revision_color,omitempty"`
+	UnknownAttrs    []xml.Attr       `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement    `xml:",any" json:"-" yaml:"-"`
+
+	elementOrder []string
 }
 
 type RevisionColor struct {
-	XMLName    xml.Name `xml:"revision_color" json:"-" yaml:"-"`
-	Name       string   `xml:"name,attr,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
-	Index      string   `xml:"index,attr,omitempty" json:"index,omitempty" yaml:"index,omitempty"`
-	ColorName  string   `xml:"color_name,attr,omitempty" json:"color_name,omitempty" yaml:"color_name,omitempty"`
-	ColorIndex string   `xml:"color_index,attr,omitempty" json:"color_index,omitempty" yaml:"color_index,omitempty"`
+	XMLName         xml.Name      `xml:"revision_color" json:"-" yaml:"-"`
+	Name            string        `xml:"name,attr,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	Index           string        `xml:"index,attr,omitempty" json:"index,omitempty" yaml:"index,omitempty"`
+	ColorName       string        `xml:"color_name,attr,omitempty" json:"color_name,omitempty" yaml:"color_name,omitempty"`
+	ColorIndex      string        `xml:"color_index,attr,omitempty" json:"color_index,omitempty" yaml:"color_index,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
 }
 
 type TagCategories struct {
-	XMLName     xml.Name       `xml:"tag_categories" json:"tag_categories" yaml:"tag_categories"`
-	TagCategory []*TagCategory `xml:"tag_category,omitempty" json:"tag_category,omitempty" yaml:"tag_category,omitempty"`
+	XMLName         xml.Name       `xml:"tag_categories" json:"tag_categories" yaml:"tag_categories"`
+	TagCategory     []*TagCategory `xml:"tag_category,omitempty" json:"tag_category,omitempty" yaml:"tag_category,omitempty"`
+	UnknownAttrs    []xml.Attr     `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement  `xml:",any" json:"-" yaml:"-"`
+
+	elementOrder []string
 }
 
 type TagCategory struct {
-	XMLName xml.Name `xml:"tag_category" json:"-" yaml:"-"`
-	Name    string   `xml:"name,attr,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	XMLName         xml.Name      `xml:"tag_category" json:"-" yaml:"-"`
+	Name            string        `xml:"name,attr,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
 }
 
 type TitlePage struct {
-	XMLName xml.Name `xml:"titlepage" json:"titlepage" yaml:"titlepage"`
-	Para    []*Para  `xml:"para,omitempty" json:"para,omitempty" yaml:"para,omitempty"`
+	XMLName         xml.Name      `xml:"titlepage" json:"titlepage" yaml:"titlepage"`
+	Para            []*Para       `xml:"para,omitempty" json:"para,omitempty" yaml:"para,omitempty"`
+	UnknownAttrs    []xml.Attr    `xml:",any,attr" json:"-" yaml:"-"`
+	UnknownElements []*AnyElement `xml:",any" json:"-" yaml:"-"`
+
+	elementOrder []string
+}
+
+// AnyElement holds an element (and its children) that is not modeled
+// by the structs in this package. It is written back out unchanged so
+// that Parse followed by ToXML does not lose data.
+type AnyElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	InnerXML string     `xml:",innerxml"`
+}
+
+// documentElements lists the child elements of the document element in the
+// order they are written when there is no previously read order to follow.
+var documentElements = []string{"info", "settings", "styles", "paragraphs", "spelling", "lists", "titlepage"}
+
+// UnmarshalXML reads the document element recording the order of its children
+// and collecting any attributes or elements that are not modeled.
+func (document *OpenScreenplay) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	document.XMLName = start.Name
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "type":
+			document.Type = attr.Value
+		case "version":
+			document.Version = attr.Value
+		default:
+			document.UnknownAttrs = append(document.UnknownAttrs, attr)
+		}
+	}
+	document.elementOrder = []string{}
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "info":
+				document.Info = new(Info)
+				err = d.DecodeElement(document.Info, &t)
+			case "settings":
+				document.Settings = new(Settings)
+				err = d.DecodeElement(document.Settings, &t)
+			case "styles":
+				document.Styles = new(Styles)
+				err = d.DecodeElement(document.Styles, &t)
+			case "paragraphs":
+				document.Paragraphs = new(Paragraphs)
+				err = d.DecodeElement(document.Paragraphs, &t)
+			case "spelling":
+				document.Spelling = new(Spelling)
+				err = d.DecodeElement(document.Spelling, &t)
+			case "lists":
+				document.Lists = new(Lists)
+				err = d.DecodeElement(document.Lists, &t)
+			case "titlepage":
+				document.TitlePage = new(TitlePage)
+				err = d.DecodeElement(document.TitlePage, &t)
+			default:
+				elem := new(AnyElement)
+				err = d.DecodeElement(elem, &t)
+				document.UnknownElements = append(document.UnknownElements, elem)
+			}
+			if err != nil {
+				return err
+			}
+			document.elementOrder = append(document.elementOrder, t.Name.Local)
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// MarshalXML writes the document element. Child elements are written in
+// the order they were read, elements added since are written afterwards.
+func (document *OpenScreenplay) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "document"}
+	start.Attr = []xml.Attr{
+		{Name: xml.Name{Local: "type"}, Value: document.Type},
+		{Name: xml.Name{Local: "version"}, Value: document.Version},
+	}
+	start.Attr = append(start.Attr, document.UnknownAttrs...)
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	elements := map[string]interface{}{
+		"info":       document.Info,
+		"settings":   document.Settings,
+		"styles":     document.Styles,
+		"paragraphs": document.Paragraphs,
+		"spelling":   document.Spelling,
+		"lists":      document.Lists,
+		"titlepage":  document.TitlePage,
+	}
+	encodeElement := func(name string) error {
+		elem, ok := elements[name]
+		if !ok {
+			return nil
+		}
+		delete(elements, name)
+		if reflect.ValueOf(elem).IsNil() {
+			return nil
+		}
+		return e.Encode(elem)
+	}
+	unknown := 0
+	for _, name := range document.elementOrder {
+		if _, ok := elements[name]; ok {
+			if err := encodeElement(name); err != nil {
+				return err
+			}
+		} else if unknown < len(document.UnknownElements) {
+			if err := e.Encode(document.UnknownElements[unknown]); err != nil {
+				return err
+			}
+			unknown++
+		}
+	}
+	for _, name := range documentElements {
+		if err := encodeElement(name); err != nil {
+			return err
+		}
+	}
+	for ; unknown < len(document.UnknownElements); unknown++ {
+		if err := e.Encode(document.UnknownElements[unknown]); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
 }
 
 func (text *Text) String() string {
@@ -331,8 +609,29 @@ func (text *Text) String() string {
 	return ""
 }
 
+// PlainText returns the text of a paragraph without any formatting
+func (para *Para) PlainText() string {
+	if para == nil {
+		return ""
+	}
+	src := []string{}
+	for _, text := range para.Text {
+		src = append(src, text.InnerText)
+	}
+	return strings.Join(src, "")
+}
+
+// IsStructural reports if the paragraph is part of the outline or set
+// aside material (e.g. a section or boneyard) rather than printed text
+func (para *Para) IsStructural() bool {
+	if para == nil || para.Style == nil {
+		return false
+	}
+	return para.Style.BaseStyleName == SectionType || para.Style.BaseStyleName == BoneyardType
+}
+
 func (para *Para) String() string {
-	if para != nil {
+	if para != nil && !para.IsStructural() {
 		src := []string{}
 		for _, text := range para.Text {
 			s := text.String()
@@ -406,83 +705,124 @@ func (doc *OpenScreenplay) String() string {
 	return ""
 }
 
-// Parse takes a byte array and returns a OpenScreenplay object and error
+// Parse takes a byte array and returns a OpenScreenplay object and error.
+// OSF 2.1 documents are detected with SniffVersion and mapped onto the
+// same struct tree as OSF 1.2 and 2.0.
 func Parse(src []byte) (*OpenScreenplay, error) {
+	if SniffVersion(src) == Version21 {
+		var err error
+		src, err = From21(src)
+		if err != nil {
+			return nil, err
+		}
+	}
 	doc := new(OpenScreenplay)
 	err := xml.Unmarshal(src, &doc)
 	return doc, err
 }
 
-// ParseFile reads in *.osf and *.fadin file and and returns
-// a OpenScreenplay object and error
+// ParseFile reads in *.osf, *.fadein and *.fdx file and and returns
+// a OpenScreenplay object and error. A *.fadein file without a
+// document.xml is an error, a *.fdx file is converted with FromFDX.
 func ParseFile(fname string) (*OpenScreenplay, error) {
 	var (
 		src []byte
 		ext string
 		err error
 	)
-	src = []byte{}
 	ext = path.Ext(fname)
 	if strings.ToLower(ext) == ".fadein" {
-		// Open a zip archive for reading.
-		r, err := zip.OpenReader(fname)
+		archive, err := OpenFadeIn(fname)
 		if err != nil {
 			return nil, err
 		}
-		defer r.Close()
-
-		// Iterate through the files in the archive,
-		// printing some of their contents.
-		for _, f := range r.File {
-			if f.Name == "document.xml" {
-				rc, err := f.Open()
-				if err != nil {
-					return nil, err
-				}
-				src, err = ioutil.ReadAll(rc)
-				if err != nil {
-					return nil, err
-				}
-				rc.Close()
-				break
-			}
-		}
-	} else {
-		src, err = ioutil.ReadFile(fname)
+		document, err := archive.Document()
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("%s, %s", fname, err)
 		}
+		return document, nil
+	}
+	src, err = ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	if strings.ToLower(ext) == ".fdx" {
+		return FromFDX(src)
 	}
-	//FIXME: Need to sniff version, 1.2 and 2.0 probably can use the same structs but
-	// 2.1 uses camel case for element names
 	return Parse(src)
 }
 
 // NewOpenScreenplay20 creates a new OpenScreenplay document set to version 2.0
-func NewOpenScreenplay20() *OpenScreenplay {
+// with a new UUID, the built in styles and default settings for US Letter
+// paper, or A4 if paper is A4Paper, so Fade In opens it as it would one of
+// its own.
+func NewOpenScreenplay20(paper ...PaperSize) *OpenScreenplay {
 	doc := new(OpenScreenplay)
 	doc.Version = "20"
 	doc.Type = "Open Screenplay Format document"
+	doc.Info = new(Info)
+	if uuid, err := newUUID(); err == nil {
+		doc.Info.UUID = uuid
+	}
+	size := LetterPaper
+	if len(paper) > 0 {
+		size = paper[0]
+	}
+	doc.Settings = NewSettings(size)
+	doc.Styles = NewStyles()
 	return doc
 }
 
-// CleanupSelfClosingElements changes something like <styles></styles> to <styles/>
+// CleanupSelfClosingElements changes something like <styles></styles> to <styles/>.
+// It works for any element name so it handles both OSF 2.0 and 2.1 names.
 func CleanupSelfClosingElements(src []byte) []byte {
-	for _, elem := range []string{"info", "settings", "styles", "style", "mark", "text", "entry", "character", "location", "scene_time", "extension", "revision_color", "tag_category", "transition", "spelling", "user_dictionary", "paragraphs", "para", "locations"} {
-		src = bytes.Replace(src, []byte("></"+elem+">"), []byte("/>"), -1)
-	}
-	for _, elem := range []string{"titlepage"} {
-		src = bytes.Replace(src, []byte("<"+elem+"></"+elem+">"), []byte("<"+elem+"/>"), -1)
+	out := make([]byte, 0, len(src))
+	for {
+		i := bytes.Index(src, []byte("></"))
+		if i < 0 {
+			break
+		}
+		// Find the element name in the closing tag
+		j := bytes.IndexByte(src[i+3:], '>')
+		if j < 0 {
+			break
+		}
+		name := src[i+3 : i+3+j]
+		// Find the start of the opening tag
+		k := bytes.LastIndexByte(src[0:i], '<')
+		if k >= 0 && bytes.HasPrefix(src[k+1:], name) &&
+			(k+1+len(name) == i || src[k+1+len(name)] == ' ') {
+			out = append(out, src[0:i]...)
+			out = append(out, '/', '>')
+		} else {
+			out = append(out, src[0:i+3+j+1]...)
+		}
+		src = src[i+3+j+1:]
 	}
-
-	return src
+	return append(out, src...)
 }
 
-// ToXML takes a OpenScreenplay struct and renders XML
+// ToXML takes a OpenScreenplay struct and renders XML using the
+// version set in the document (e.g. "20" or "21").
 func (document *OpenScreenplay) ToXML() ([]byte, error) {
-	src, err := xml.MarshalIndent(document, "", "    ")
+	return document.ToXMLVersion(document.Version)
+}
+
+// ToXMLVersion takes a OpenScreenplay struct and renders XML for a specific
+// version of OSF. Versions "12" and "20" share element names while "21"
+// uses camelCase element and attribute names.
+func (document *OpenScreenplay) ToXMLVersion(version string) ([]byte, error) {
+	doc := *document
+	doc.Version = version
+	src, err := xml.MarshalIndent(&doc, "", "    ")
 	if err != nil {
 		return nil, err
 	}
+	if version == Version21 {
+		src, err = To21(src)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return CleanupSelfClosingElements(src), nil
 }
@@ -0,0 +1,213 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+const (
+	// Version12 is the version attribute value used by OSF 1.2 documents
+	Version12 = "12"
+	// Version20 is the version attribute value used by OSF 2.0 documents
+	Version20 = "20"
+	// Version21 is the version attribute value used by OSF 2.1 documents
+	Version21 = "21"
+)
+
+// osf21Names maps the OSF 2.0 element and attribute names which do not
+// follow the simple snake_case to camelCase rule onto their OSF 2.1 names.
+// All other names are converted mechanically (e.g. page_width <-> pageWidth).
+var osf21Names = map[string]string{
+	"basestylename":         "baseStyleName",
+	"keepwithnext":          "keepWithNext",
+	"leftindent":            "leftIndent",
+	"rightindent":           "rightIndent",
+	"spacebefore":           "spaceBefore",
+	"linespacing":           "lineSpacing",
+	"pagebreakbefore":       "pageBreakBefore",
+	"dualdialogue":          "dualDialogue",
+	"allcaps":               "allCaps",
+	"bgcolor":               "bgColor",
+	"pagecount":             "pageCount",
+	"titlepage":             "titlePage",
+	"normal_linesperinch":   "normalLinesPerInch",
+	"pagenumber_format":     "pageNumberFormat",
+	"pagenumber_start":      "pageNumberStart",
+	"pagenumber_first":      "pageNumberFirst",
+	"pagenumber_mode":       "pageNumberMode",
+	"scenenumber_mode":      "sceneNumberMode",
+	"scenenumber_skip_io":   "sceneNumberSkipIO",
+	"scenenumber_start":     "sceneNumberStart",
+	"scenenumber_format":    "sceneNumberFormat",
+	"scenenumber_position":  "sceneNumberPosition",
+	"dialoguenumber_start":  "dialogueNumberStart",
+	"dialoguenumber_format": "dialogueNumberFormat",
+}
+
+// osf20Names is the inverse of osf21Names
+var osf20Names = map[string]string{}
+
+func init() {
+	for k, v := range osf21Names {
+		osf20Names[v] = k
+	}
+}
+
+// to21Name converts an OSF 2.0 element or attribute name to OSF 2.1
+func to21Name(name string) string {
+	if s, ok := osf21Names[name]; ok {
+		return s
+	}
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][0:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// to20Name converts an OSF 2.1 element or attribute name to OSF 2.0
+func to20Name(name string) string {
+	if s, ok := osf20Names[name]; ok {
+		return s
+	}
+	var sb strings.Builder
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				sb.WriteByte('_')
+			}
+			r = r + ('a' - 'A')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// hasUpperCase returns true if name contains an ASCII upper case letter
+func hasUpperCase(name string) bool {
+	return strings.IndexFunc(name, func(r rune) bool {
+		return r >= 'A' && r <= 'Z'
+	}) >= 0
+}
+
+// SniffVersion looks at the root element of an OSF document and returns
+// the version it is written in, e.g. "12", "20" or "21". The version
+// attribute is used when present, otherwise camelCase element names are
+// taken to indicate an OSF 2.1 document.
+func SniffVersion(src []byte) string {
+	decoder := xml.NewDecoder(bytes.NewReader(src))
+	elementCount := 0
+	for elementCount < 8 {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		if elem, ok := token.(xml.StartElement); ok {
+			if elementCount == 0 {
+				for _, attr := range elem.Attr {
+					if attr.Name.Local == "version" {
+						switch strings.TrimSpace(attr.Value) {
+						case "21", "2.1":
+							return Version21
+						case "20", "2.0":
+							return Version20
+						case "12", "1.2":
+							return Version12
+						case "":
+						default:
+							return attr.Value
+						}
+					}
+				}
+			}
+			if hasUpperCase(elem.Name.Local) {
+				return Version21
+			}
+			elementCount++
+		}
+	}
+	return Version20
+}
+
+// renameElements copies the XML in src renaming each element and attribute
+// using the rename function.
+func renameElements(src []byte, rename func(string) string) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(src))
+	buf := new(bytes.Buffer)
+	encoder := xml.NewEncoder(buf)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			t.Name.Local = rename(t.Name.Local)
+			attrs := make([]xml.Attr, len(t.Attr))
+			for i, attr := range t.Attr {
+				attr.Name.Local = rename(attr.Name.Local)
+				attrs[i] = attr
+			}
+			t.Attr = attrs
+			token = t
+		case xml.EndElement:
+			t.Name.Local = rename(t.Name.Local)
+			token = t
+		}
+		if err := encoder.EncodeToken(token); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// From21 converts the element and attribute names of an OSF 2.1 document
+// to their OSF 2.0 equivalents so it can be unmarshaled into an OpenScreenplay.
+func From21(src []byte) ([]byte, error) {
+	return renameElements(src, to20Name)
+}
+
+// To21 converts the element and attribute names of an OSF 2.0 document
+// to their OSF 2.1 (camelCase) equivalents.
+func To21(src []byte) ([]byte, error) {
+	return renameElements(src, to21Name)
+}
@@ -30,10 +30,16 @@
 package osf
 
 import (
+	"archive/zip"
 	"bytes"
+	"encoding/xml"
 	"fmt"
+	"io"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 
 	// 3rd Party packagess
@@ -96,6 +102,224 @@ func TestToYAML(t *testing.T) {
 	}
 }
 
+func TestSniffVersion(t *testing.T) {
+	for fname, expected := range map[string]string{
+		"sample-01.osf": Version12,
+		"OSF-2.0.xml":   Version20,
+		"OSF-2.1.xml":   Version21,
+	} {
+		src, err := os.ReadFile(path.Join("testdata", fname))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if result := SniffVersion(src); result != expected {
+			t.Errorf("expected version %q, got %q for %s", expected, result, fname)
+		}
+	}
+}
+
+func TestParseVersion21(t *testing.T) {
+	doc, err := ParseFile(path.Join("testdata", "OSF-2.1.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Version != Version21 {
+		t.Errorf("expected version %q, got %q", Version21, doc.Version)
+	}
+	if doc.Info == nil || doc.Info.WrittenBy != "Jane Doe" || doc.Info.PageCount != "1" {
+		t.Errorf("expected info to be populated, got %+v", doc.Info)
+	}
+	if doc.Settings == nil || doc.Settings.NormalLinesPerInch != "6.0" || doc.Settings.PageWidth != "2159" {
+		t.Errorf("expected settings to be populated, got %+v", doc.Settings)
+	}
+	if doc.Styles == nil || len(doc.Styles.Style) != 7 {
+		t.Fatalf("expected 7 styles, got %+v", doc.Styles)
+	}
+	if style := doc.Styles.Style[3]; style.BaseStyleName != GeneralType || style.KeepWithNext != "1" || style.LeftIdent != "635" {
+		t.Errorf("expected Character style to be populated, got %+v", style)
+	}
+	if doc.Paragraphs == nil || len(doc.Paragraphs.Para) != 7 {
+		t.Fatalf("expected 7 paragraphs, got %+v", doc.Paragraphs)
+	}
+	para := doc.Paragraphs.Para[1]
+	if para.PageNumber != "1" || para.Style == nil || para.Style.BaseStyleName != SceneHeadingType {
+		t.Errorf("expected a scene heading on page 1, got %+v", para)
+	}
+	if doc.TitlePage == nil || len(doc.TitlePage.Para) != 2 {
+		t.Errorf("expected a title page with two paragraphs, got %+v", doc.TitlePage)
+	}
+	if doc.Spelling == nil || doc.Spelling.UserDictionary == nil {
+		t.Errorf("expected a user dictionary, got %+v", doc.Spelling)
+	}
+	if doc.Lists == nil || doc.Lists.SceneIntros == nil || len(doc.Lists.SceneIntros.SceneIntro) != 3 {
+		t.Fatalf("expected three scene intros, got %+v", doc.Lists)
+	}
+	if doc.Lists.RevisionColors == nil || doc.Lists.RevisionColors.RevisionColor[1].ColorName != "Blue" {
+		t.Errorf("expected revision colors, got %+v", doc.Lists.RevisionColors)
+	}
+	if doc.Lists.TagCategories == nil || len(doc.Lists.TagCategories.TagCategory) != 1 {
+		t.Errorf("expected tag categories, got %+v", doc.Lists.TagCategories)
+	}
+}
+
+func TestToXMLVersion21(t *testing.T) {
+	for _, fname := range []string{"OSF-2.0.xml", "OSF-2.1.xml", "sample-02.osf"} {
+		doc, err := ParseFile(path.Join("testdata", fname))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		expected, err := doc.ToXMLVersion(Version20)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		src, err := doc.ToXMLVersion(Version21)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		for _, elem := range []string{"<titlePage", "baseStyleName=", "<sceneIntros>"} {
+			if doc.TitlePage == nil && elem == "<titlePage" {
+				continue
+			}
+			if !bytes.Contains(src, []byte(elem)) {
+				t.Errorf("expected %s in OSF 2.1 output for %s", elem, fname)
+			}
+		}
+		if SniffVersion(src) != Version21 {
+			t.Errorf("expected OSF 2.1 output for %s", fname)
+		}
+		doc21, err := Parse(src)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		result, err := doc21.ToXMLVersion(Version20)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if !bytes.Equal(expected, result) {
+			t.Errorf("expected OSF 2.0 -> 2.1 -> 2.0 to round trip for %s\n%s\n%s", fname, expected, result)
+		}
+	}
+}
+
+// canonicalXML returns a list of tokens from src with attributes sorted
+// and formatting whitespace between elements removed so two XML
+// documents can be compared for equivalence. An attribute with an empty
+// value is treated the same as a missing attribute.
+func canonicalXML(src []byte) ([]string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(src))
+	tokens := []string{}
+	var (
+		prev    xml.Token
+		pending string
+	)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			attrs := []string{}
+			for _, attr := range t.Attr {
+				if attr.Value != "" {
+					attrs = append(attrs, fmt.Sprintf("%s=%q", attr.Name.Local, attr.Value))
+				}
+			}
+			sort.Strings(attrs)
+			if strings.TrimSpace(pending) != "" {
+				tokens = append(tokens, pending)
+			}
+			pending = ""
+			tokens = append(tokens, fmt.Sprintf("<%s %s>", t.Name.Local, strings.Join(attrs, " ")))
+			prev = t
+		case xml.EndElement:
+			// Whitespace is only significant as the content of a text element
+			if _, ok := prev.(xml.StartElement); (ok && t.Name.Local == "text") || strings.TrimSpace(pending) != "" {
+				tokens = append(tokens, pending)
+			}
+			pending = ""
+			tokens = append(tokens, fmt.Sprintf("</%s>", t.Name.Local))
+			prev = t
+		case xml.CharData:
+			pending += string(t)
+		}
+	}
+	return tokens, nil
+}
+
+func TestLosslessRoundTrip(t *testing.T) {
+	fnames, err := filepath.Glob(path.Join("testdata", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fname := range fnames {
+		ext := path.Ext(fname)
+		if ext != ".osf" && ext != ".xml" && ext != ".fadein" {
+			continue
+		}
+		var src []byte
+		if ext == ".fadein" {
+			r, err := zip.OpenReader(fname)
+			if err != nil {
+				t.Error(err)
+				continue
+			}
+			for _, f := range r.File {
+				if f.Name == "document.xml" {
+					rc, _ := f.Open()
+					src, err = io.ReadAll(rc)
+					rc.Close()
+				}
+			}
+			r.Close()
+		} else {
+			src, err = os.ReadFile(fname)
+		}
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		doc, err := Parse(src)
+		if err != nil {
+			t.Errorf("%s, %s", fname, err)
+			continue
+		}
+		result, err := doc.ToXML()
+		if err != nil {
+			t.Errorf("%s, %s", fname, err)
+			continue
+		}
+		expected, err := canonicalXML(src)
+		if err != nil {
+			t.Errorf("%s, %s", fname, err)
+			continue
+		}
+		got, err := canonicalXML(result)
+		if err != nil {
+			t.Errorf("%s, %s", fname, err)
+			continue
+		}
+		if len(expected) != len(got) {
+			t.Errorf("expected %d tokens, got %d for %s", len(expected), len(got), fname)
+		}
+		for i := 0; i < len(expected) && i < len(got); i++ {
+			if expected[i] != got[i] {
+				t.Errorf("expected %s, got %s at token %d for %s", expected[i], got[i], i, fname)
+				break
+			}
+		}
+	}
+}
+
 func TestMain(m *testing.M) {
 	// Setup everything, process flags, etc.
 	os.Exit(m.Run())
@@ -0,0 +1,158 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"strconv"
+)
+
+// OutlineItem is a section or scene in a screenplay's outline
+type OutlineItem struct {
+	// Level is the depth in the outline, sections start at one and a
+	// scene is one level below the section it is in.
+	Level int    `json:"level" yaml:"level"`
+	Title string `json:"title" yaml:"title"`
+	// SceneNumber is the scene's number if scenes are numbered
+	SceneNumber string `json:"scene_number,omitempty" yaml:"scene_number,omitempty"`
+	Synopsis    string `json:"synopsis,omitempty" yaml:"synopsis,omitempty"`
+	Note        string `json:"note,omitempty" yaml:"note,omitempty"`
+	Para        *Para  `json:"-" yaml:"-"`
+}
+
+// SectionLevel returns the outline depth of a section paragraph, it is
+// zero for other paragraphs
+func (para *Para) SectionLevel() int {
+	if para == nil || para.Style == nil || para.Style.BaseStyleName != SectionType {
+		return 0
+	}
+	level, err := strconv.Atoi(para.Level)
+	if err != nil || level < 1 {
+		return 1
+	}
+	return level
+}
+
+// NewSection creates a section paragraph, a bookmarked heading in the
+// outline that is not printed. Call AddStructuralStyles after adding
+// sections to a document.
+func NewSection(title string, level int) *Para {
+	para := new(Para)
+	para.Bookmark = title
+	para.Level = strconv.Itoa(level)
+	para.Style = new(Style)
+	para.Style.BaseStyleName = SectionType
+	para.Text = []*Text{{InnerText: title}}
+	return para
+}
+
+// AddStructuralStyles adds the Section and Boneyard styles to the
+// document's styles if it has paragraphs in them and doesn't define
+// them. Fade In lists them with its own styles and shows the paragraphs
+// in grey, it prints them too so remove them before printing from Fade
+// In.
+func (document *OpenScreenplay) AddStructuralStyles() {
+	if document == nil || document.Paragraphs == nil {
+		return
+	}
+	used := map[string]bool{}
+	for _, para := range document.Paragraphs.Para {
+		if para.IsStructural() {
+			used[para.Style.BaseStyleName] = true
+		}
+	}
+	for _, style := range structuralStyles {
+		if used[style.Name] && document.findStyle(style.Name) == nil {
+			if document.Styles == nil {
+				document.Styles = new(Styles)
+			}
+			s := *style
+			document.Styles.Style = append(document.Styles.Style, &s)
+		}
+	}
+}
+
+// Outline returns the sections and scene headings of the screenplay in
+// order along with their scene numbers, synopses and notes
+func (document *OpenScreenplay) Outline() []*OutlineItem {
+	items := []*OutlineItem{}
+	if document == nil || document.Paragraphs == nil {
+		return items
+	}
+	sceneNumbers := document.shownSceneNumbers()
+	level := 0
+	for _, para := range document.Paragraphs.Para {
+		item := &OutlineItem{
+			Title:       para.PlainText(),
+			SceneNumber: sceneNumbers[para],
+			Synopsis:    para.Synopsis,
+			Note:        para.Note,
+			Para:        para,
+		}
+		switch {
+		case para.SectionLevel() > 0:
+			level = para.SectionLevel()
+			item.Level = level
+		case para.Style != nil && para.Style.BaseStyleName == SceneHeadingType:
+			item.Level = level + 1
+		default:
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// Notes returns the paragraphs that have a note
+func (document *OpenScreenplay) Notes() []*Para {
+	paras := []*Para{}
+	if document == nil || document.Paragraphs == nil {
+		return paras
+	}
+	for _, para := range document.Paragraphs.Para {
+		if para.Note != "" {
+			paras = append(paras, para)
+		}
+	}
+	return paras
+}
+
+// Boneyard returns the paragraphs that have been set aside
+func (document *OpenScreenplay) Boneyard() []*Para {
+	paras := []*Para{}
+	if document == nil || document.Paragraphs == nil {
+		return paras
+	}
+	for _, para := range document.Paragraphs.Para {
+		if para.Style != nil && para.Style.BaseStyleName == BoneyardType {
+			paras = append(paras, para)
+		}
+	}
+	return paras
+}
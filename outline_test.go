@@ -0,0 +1,162 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"strings"
+	"testing"
+
+	// My Packages
+	"github.com/rsdoiel/fountain"
+)
+
+func TestOutline(t *testing.T) {
+	src := []byte(`# Act One
+
+= The hero wakes up.
+
+## Morning
+
+INT. ROOM - DAY
+
+= She is late.
+= Very late.
+
+She runs. [[check the blocking]]
+
+[[A note
+that runs on
+for three lines]]
+
+/*
+INT. GONE - NIGHT
+
+BOB
+Hi.
+*/
+
+BOB
+Hello.
+
+# Act Two
+
+EXT. STREET - DAY
+
+She arrives.
+`)
+	// fromFountain parses src and converts it to OSF
+	fromFountain := func(src []byte) *OpenScreenplay {
+		screenplay, err := fountain.Parse(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		document := NewOpenScreenplay20()
+		document.FromFountain(screenplay)
+		return document
+	}
+	document := fromFountain(src)
+	expected := []*OutlineItem{
+		{Level: 1, Title: "Act One", Synopsis: "The hero wakes up."},
+		{Level: 2, Title: "Morning"},
+		{Level: 3, Title: "INT. ROOM - DAY", Synopsis: "She is late.\nVery late."},
+		{Level: 1, Title: "Act Two"},
+		{Level: 2, Title: "EXT. STREET - DAY"},
+	}
+	outline := document.Outline()
+	if len(outline) != len(expected) {
+		t.Fatalf("expected %d outline items, got %d", len(expected), len(outline))
+	}
+	for i, item := range outline {
+		if item.Level != expected[i].Level || item.Title != expected[i].Title || item.Synopsis != expected[i].Synopsis {
+			t.Errorf("item %d, expected %+v, got %+v", i, expected[i], item)
+		}
+	}
+	if outline[0].Para.Bookmark != "Act One" || !outline[0].Para.IsStructural() {
+		t.Errorf("expected a bookmarked structural section, got %+v", outline[0].Para)
+	}
+
+	notes := document.Notes()
+	if len(notes) != 1 {
+		t.Fatalf("expected 1 paragraph with notes, got %d", len(notes))
+	}
+	if s := notes[0].PlainText(); s != "She runs." {
+		t.Errorf("expected note on %q, got %q", "She runs.", s)
+	}
+	if s := notes[0].Note; s != "check the blocking\nA note\nthat runs on\nfor three lines" {
+		t.Errorf("unexpected note %q", s)
+	}
+
+	boneyard := document.Boneyard()
+	if len(boneyard) != 1 {
+		t.Fatalf("expected 1 boneyard paragraph, got %d", len(boneyard))
+	}
+	if s := boneyard[0].PlainText(); s != "INT. GONE - NIGHT\n\nBOB\nHi." {
+		t.Errorf("unexpected boneyard %q", s)
+	}
+	if s := document.String(); strings.Contains(s, "Act One") || strings.Contains(s, "GONE") {
+		t.Errorf("structural paragraphs should not be printed, got\n%s", s)
+	}
+
+	// The structural styles are defined in the document for Fade In
+	for _, name := range []string{SectionType, BoneyardType} {
+		if style := document.findStyle(name); style == nil || style.Color != "#7F7F7F" {
+			t.Errorf("expected %s style in the document, got %+v", name, style)
+		}
+	}
+	if style, err := document.StyleOf(outline[0].Para); err != nil || !style.Underline {
+		t.Errorf("expected the Section style, got %+v, %v", style, err)
+	}
+	if other := fromFountain([]byte("INT. ROOM - DAY\n\nShe runs.\n")); other.findStyle(SectionType) != nil || other.findStyle(BoneyardType) != nil {
+		t.Errorf("expected no structural styles without sections or boneyard")
+	}
+
+	// Sections before the first scene heading are not title page
+	if outline := fromFountain(append([]byte("Title: Test\n\n"), src...)).Outline(); len(outline) != len(expected) {
+		t.Errorf("with a title page, expected %d outline items, got %d", len(expected), len(outline))
+	}
+
+	// The outline survives a round trip through Fountain
+	document = fromFountain([]byte(document.ToFountain()))
+	outline = document.Outline()
+	if len(outline) != len(expected) {
+		t.Fatalf("round trip, expected %d outline items, got %d", len(expected), len(outline))
+	}
+	for i, item := range outline {
+		if item.Level != expected[i].Level || item.Title != expected[i].Title || item.Synopsis != expected[i].Synopsis {
+			t.Errorf("round trip, item %d, expected %+v, got %+v", i, expected[i], item)
+		}
+	}
+	if notes := document.Notes(); len(notes) != 1 || notes[0].Note != "check the blocking\nA note\nthat runs on\nfor three lines" {
+		t.Errorf("round trip, unexpected notes %+v", notes)
+	}
+	if boneyard := document.Boneyard(); len(boneyard) != 1 || boneyard[0].PlainText() != "INT. GONE - NIGHT\n\nBOB\nHi." {
+		t.Errorf("round trip, unexpected boneyard %+v", boneyard)
+	}
+}
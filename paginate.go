@@ -0,0 +1,616 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Page is a page of the screenplay as laid out by Layout
+type Page struct {
+	// Number is the page number, e.g. "12"
+	Number string
+	// Lines holds the lines of the page from the top margin down
+	Lines []*Line
+}
+
+// Line is a line of laid out text, blank lines have no Para or Text
+type Line struct {
+	// Para is the paragraph the line comes from, (MORE) and (CONT'D)
+	// lines belong to the dialogue they continue
+	Para *Para
+	// Style is the base style name the line was laid out with
+	Style string
+	// Indent is the distance from the left margin to the text
+	Indent Length
+	// Width is the width available to the text
+	Width Length
+	// Align is one of LeftAlignment, CenterAlignment or RightAlignment
+	Align string
+	// Text holds the formatted runs of text on the line
+	Text []*Text
+	// Start is the offset of the line's first character in the
+	// paragraph's text, it is -1 for (MORE) and (CONT'D) lines
+	Start int
+}
+
+// String returns the text of the line without formatting
+func (line *Line) String() string {
+	if line == nil {
+		return ""
+	}
+	src := []string{}
+	for _, text := range line.Text {
+		src = append(src, text.InnerText)
+	}
+	return strings.Join(src, "")
+}
+
+// copyText returns a copy of text's formatting holding s
+func copyText(text *Text, s string) *Text {
+	return &Text{
+		Underline:     text.Underline,
+		Italic:        text.Italic,
+		Bold:          text.Bold,
+		Strikethrough: text.Strikethrough,
+		AllCaps:       text.AllCaps,
+		Revision:      text.Revision,
+		InnerText:     s,
+	}
+}
+
+// sliceRuns returns the runs of text between the rune offsets start and end
+func sliceRuns(runs []*Text, start, end int) []*Text {
+	out := []*Text{}
+	offset := 0
+	for _, text := range runs {
+		r := []rune(text.InnerText)
+		from, to := start-offset, end-offset
+		offset += len(r)
+		if to <= 0 || from >= len(r) {
+			continue
+		}
+		if from < 0 {
+			from = 0
+		}
+		if to > len(r) {
+			to = len(r)
+		}
+		out = append(out, copyText(text, string(r[from:to])))
+	}
+	return out
+}
+
+// wrapRuns breaks runs of text into lines no more than width characters
+// wide. Lines break at spaces where possible and always at "\n".
+func wrapRuns(runs []*Text, width int) [][]*Text {
+	src := []rune{}
+	for _, text := range runs {
+		src = append(src, []rune(text.InnerText)...)
+	}
+	if width < 1 {
+		width = 1
+	}
+	lines := [][]*Text{}
+	start := 0
+	for start <= len(src) {
+		// The end of this hard line
+		end := start
+		for end < len(src) && src[end] != '\n' {
+			end++
+		}
+		for {
+			if end-start <= width {
+				lines = append(lines, sliceRuns(runs, start, end))
+				break
+			}
+			brk := start + width
+			for brk > start && src[brk] != ' ' {
+				brk--
+			}
+			next := brk + 1
+			if brk == start {
+				// No space to break at so break the word
+				brk, next = start+width, start+width
+			}
+			// Spaces at the end of a line are dropped
+			trim := brk
+			for trim > start && src[trim-1] == ' ' {
+				trim--
+			}
+			lines = append(lines, sliceRuns(runs, start, trim))
+			for next < end && src[next] == ' ' {
+				next++
+			}
+			start = next
+		}
+		start = end + 1
+	}
+	return lines
+}
+
+// layoutBlock is a paragraph laid out as lines
+type layoutBlock struct {
+	para      *Para
+	style     *EffectiveStyle
+	space     int
+	pageBreak bool
+	lines     []*Line
+	// speaker is the character whose dialogue this is, for (CONT'D)
+	speaker string
+	// lockedPage is the page the paragraph is locked to
+	lockedPage string
+}
+
+// splittable reports if the block may break across pages
+func (block *layoutBlock) splittable() bool {
+	switch block.style.Name {
+	case SceneHeadingType, CharacterType, ParentheticalType, TransitionType, ShotType:
+		return false
+	}
+	return !block.style.KeepWithNext
+}
+
+// isDialogue reports if a split needs (MORE) and (CONT'D)
+func (block *layoutBlock) isDialogue() bool {
+	return block.style.Name == DialogueType || block.style.Name == SingingType
+}
+
+// minLines is the fewest lines of the block that can start a page, two
+// lines of text (and a (MORE)) if it can be split
+func (block *layoutBlock) minLines() int {
+	if !block.splittable() || len(block.lines) < 4 {
+		return len(block.lines)
+	}
+	if block.isDialogue() {
+		return 3
+	}
+	return 2
+}
+
+// layoutLine creates a line in the block's style
+func (document *OpenScreenplay) layoutLine(para *Para, style *EffectiveStyle, text []*Text) *Line {
+	return &Line{
+		Para:   para,
+		Style:  style.Name,
+		Indent: style.LeftIndent,
+		Width:  document.Settings.TextWidth() - style.LeftIndent - style.RightIndent,
+		Align:  style.Align,
+		Text:   text,
+	}
+}
+
+// layoutBlocks lays out each printed paragraph as lines
+func (document *OpenScreenplay) layoutBlocks() []*layoutBlock {
+	blocks := []*layoutBlock{}
+	if document.Paragraphs == nil {
+		return blocks
+	}
+	settings := document.Settings
+	spacing := settings.Spacing()
+	contLabel := settings.ContLabel()
+	speaker, lastSpeaker := "", ""
+	for _, para := range document.Paragraphs.Para {
+		if para.IsStructural() || strings.TrimSpace(para.PlainText()) == "" {
+			continue
+		}
+		style, _ := document.StyleOf(para)
+		prefix := 0
+		runs := []*Text{}
+		for _, text := range para.Text {
+			runs = append(runs, copyText(text, text.InnerText))
+		}
+		switch style.Name {
+		case SceneHeadingType:
+			lastSpeaker = ""
+		case CharacterType:
+			speaker = strings.ToUpper(strings.TrimSpace(para.PlainText()))
+			if i := strings.Index(speaker, "("); i > 0 {
+				speaker = strings.TrimSpace(speaker[:i])
+			}
+			// The same character speaking again in a scene continues
+			if settings.Flag(DialogueContinuesFlag) && speaker == lastSpeaker &&
+				!strings.Contains(strings.ToUpper(para.PlainText()), strings.ToUpper(contLabel)) {
+				runs = append(runs, &Text{InnerText: " " + contLabel})
+			}
+			lastSpeaker = speaker
+		case ParentheticalType:
+			if s := strings.TrimSpace(para.PlainText()); !strings.HasPrefix(s, "(") {
+				runs = append([]*Text{{InnerText: "("}}, append(runs, &Text{InnerText: ")"})...)
+				prefix = 1
+			}
+		}
+		src := []rune{}
+		for _, text := range runs {
+			if style.AllCaps || text.AllCaps == AllCapsStyle {
+				text.InnerText = strings.ToUpper(text.InnerText)
+			}
+			src = append(src, []rune(text.InnerText)...)
+		}
+		block := &layoutBlock{
+			para:      para,
+			style:     style,
+			space:     int(math.Round(style.SpaceBefore * spacing)),
+			pageBreak: style.PageBreakBefore,
+			speaker:   speaker,
+		}
+		width := document.layoutLine(para, style, nil).Width
+		chars := int(math.Floor(width.Inches()*120/style.Size + 0.001))
+		extra := int(math.Round(style.LineSpacing)) - 1
+		pos := 0
+		for i, text := range wrapRuns(runs, chars) {
+			if i > 0 {
+				for j := 0; j < extra; j++ {
+					block.lines = append(block.lines, new(Line))
+				}
+			}
+			line := document.layoutLine(para, style, text)
+			// Only spaces and newlines are dropped between lines
+			r := []rune(line.String())
+			for pos < len(src) && !runesAt(src, pos, r) {
+				pos++
+			}
+			if line.Start = pos - prefix; line.Start < 0 {
+				line.Start = 0
+			}
+			pos += len(r)
+			block.lines = append(block.lines, line)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// runesAt reports if s is found in src at offset i
+func runesAt(src []rune, i int, s []rune) bool {
+	if i+len(s) > len(src) {
+		return false
+	}
+	for j, r := range s {
+		if src[i+j] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// keepLines is the number of lines that must follow blocks[i] on its
+// page because it keeps with the next paragraph
+func keepLines(blocks []*layoutBlock, i int) int {
+	if !blocks[i].style.KeepWithNext || i+1 >= len(blocks) || blocks[i+1].pageBreak {
+		return 0
+	}
+	next := blocks[i+1]
+	if next.style.KeepWithNext {
+		return next.space + len(next.lines) + keepLines(blocks, i+1)
+	}
+	return next.space + next.minLines()
+}
+
+// Layout paginates the screenplay using its page geometry, margins,
+// lines per inch and styles. Paragraphs that keep with the next stay on
+// its page (so scene headings and character names are never left at the
+// bottom of a page), action breaks between lines and dialogue breaks
+// with (MORE) and (CONT'D). Sections, boneyard and empty paragraphs are
+// not printed.
+//
+// When pages are locked (see LockPages) paragraphs stay on the page in
+// their PageNumber. Material that no longer fits on its page continues on
+// A pages (e.g. 12A, 12B or A12, B12 depending on the pagenumber_mode)
+// and a page followed by pages that have been cut is numbered with the
+// range it covers, e.g. 13-15.
+func (document *OpenScreenplay) Layout() []*Page {
+	settings := document.Settings
+	linesPerPage := settings.LinesPerPage()
+	if linesPerPage < 1 {
+		linesPerPage = 1
+	}
+	dialogueBreaks := settings == nil || settings.DialoguePageBreaks == "" || ParseBool(settings.DialoguePageBreaks)
+	characterStyle, _ := document.ResolveStyle(CharacterType)
+
+	moreLine := func(block *layoutBlock) *Line {
+		line := document.layoutLine(block.para, characterStyle, []*Text{{InnerText: settings.MoreLabel()}})
+		line.Start = -1
+		return line
+	}
+	contLine := func(block *layoutBlock) *Line {
+		line := document.layoutLine(block.para, characterStyle, []*Text{{InnerText: block.speaker + " " + settings.ContLabel()}})
+		line.Start = -1
+		return line
+	}
+
+	blocks := document.layoutBlocks()
+	locked := settings.Flag(PagesLockedFlag) && lockPages(blocks)
+	used := map[string]bool{}
+	for _, block := range blocks {
+		used[block.lockedPage] = true
+	}
+	// next numbers the page following the current one, when pages are
+	// locked it is an A page, e.g. 12A
+	next := func(page *Page) string {
+		if !locked {
+			return ""
+		}
+		prev := page.Number
+		if _, to := pageRange(prev); strings.Contains(prev, "-") && to >= 0 {
+			// Pages after 13-15 are 15A, 15B
+			prev = strconv.Itoa(to)
+		}
+		number := insertedNumber(prev, settings.PageMode(), false, used)
+		used[number] = true
+		return number
+	}
+
+	pages := []*Page{}
+	page := new(Page)
+	if len(blocks) > 0 {
+		page.Number = blocks[0].lockedPage
+	}
+	newPage := func(number string) {
+		pages = append(pages, page)
+		page = &Page{Number: number}
+	}
+	group := page.Number
+	for i := 0; i < len(blocks); i++ {
+		block := blocks[i]
+		if locked && block.lockedPage != group {
+			// A locked page starts a new page unless the paragraph
+			// before it has already continued onto it
+			group = block.lockedPage
+			if page.Number != group {
+				if len(page.Lines) > 0 {
+					newPage(group)
+				} else {
+					page.Number = group
+				}
+			}
+		}
+		if block.pageBreak && len(page.Lines) > 0 {
+			newPage(next(page))
+		}
+		space := block.space
+		if len(page.Lines) == 0 {
+			space = 0
+		}
+		filled := len(page.Lines) + space
+		keep := keepLines(blocks, i)
+		if filled+len(block.lines)+keep <= linesPerPage || len(page.Lines) == 0 && filled+len(block.lines) <= linesPerPage {
+			for j := 0; j < space; j++ {
+				page.Lines = append(page.Lines, new(Line))
+			}
+			page.Lines = append(page.Lines, block.lines...)
+			continue
+		}
+		more := dialogueBreaks && block.isDialogue() && block.speaker != ""
+		take := 0
+		switch {
+		case len(page.Lines) == 0:
+			// The block is longer than a page
+			take = linesPerPage - filled
+			if more {
+				take--
+			}
+			if take < 1 {
+				take = 1
+			}
+		case block.splittable():
+			// At least two lines on each page
+			take = linesPerPage - filled
+			if more {
+				take--
+			}
+			if take > len(block.lines)-2 {
+				take = len(block.lines) - 2
+			}
+		}
+		if take >= 2 || take >= 1 && len(page.Lines) == 0 {
+			for j := 0; j < space; j++ {
+				page.Lines = append(page.Lines, new(Line))
+			}
+			page.Lines = append(page.Lines, block.lines[0:take]...)
+			rest := &layoutBlock{
+				para:       block.para,
+				style:      block.style,
+				lines:      block.lines[take:],
+				speaker:    block.speaker,
+				lockedPage: block.lockedPage,
+			}
+			if more {
+				page.Lines = append(page.Lines, moreLine(block))
+				rest.lines = append([]*Line{contLine(block)}, rest.lines...)
+			}
+			// The last paragraph of a locked page continues onto the
+			// next locked page as it did when the pages were locked
+			if locked && i+1 < len(blocks) && blocks[i+1].lockedPage != block.lockedPage {
+				newPage(blocks[i+1].lockedPage)
+			} else {
+				newPage(next(page))
+			}
+			blocks[i] = rest
+			i--
+			continue
+		}
+		// Move the block to the next page, a speech broken between a
+		// parenthetical and dialogue continues too.
+		if dialogueBreaks && i > 0 && blocks[i-1].speaker == block.speaker && block.speaker != "" &&
+			(block.isDialogue() || block.style.Name == ParentheticalType) &&
+			(blocks[i-1].isDialogue() || blocks[i-1].style.Name == ParentheticalType) &&
+			len(page.Lines) < linesPerPage {
+			page.Lines = append(page.Lines, moreLine(block))
+			blocks[i] = &layoutBlock{
+				para:       block.para,
+				style:      block.style,
+				lines:      append([]*Line{contLine(block)}, block.lines...),
+				speaker:    block.speaker,
+				lockedPage: block.lockedPage,
+			}
+		}
+		newPage(next(page))
+		i--
+	}
+	if len(page.Lines) > 0 || len(pages) == 0 {
+		pages = append(pages, page)
+	}
+	first := settings.FirstPageNumber()
+	if locked {
+		combinePages(pages, first)
+		return pages
+	}
+	for i, page := range pages {
+		page.Number = strconv.Itoa(first + i)
+	}
+	return pages
+}
+
+// lockPages sets the page each block is locked to from its paragraph's
+// PageNumber, paragraphs added since the pages were locked are on the
+// page of the paragraph before them. It reports false if no paragraph
+// has a page number.
+func lockPages(blocks []*layoutBlock) bool {
+	number := ""
+	for _, block := range blocks {
+		if number = strings.TrimSpace(block.para.PageNumber); number != "" {
+			break
+		}
+	}
+	if number == "" {
+		return false
+	}
+	for _, block := range blocks {
+		if s := strings.TrimSpace(block.para.PageNumber); s != "" {
+			number = s
+		}
+		block.lockedPage = number
+	}
+	return true
+}
+
+// pageRange returns the first and last pages a locked page number
+// covers, e.g. 12 and 12 for "12", "12A" or "A12" and 10 and 12 for
+// "10-12". They are -1 if it isn't numbered.
+func pageRange(number string) (int, int) {
+	from, to := -1, -1
+	for i, s := range strings.SplitN(number, "-", 2) {
+		if m := lockedNumberRE.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(s))); m != nil {
+			n, _ := strconv.Atoi(m[2])
+			if i == 0 {
+				from = n
+			}
+			to = n
+		}
+	}
+	if from < 0 {
+		return -1, -1
+	}
+	return from, to
+}
+
+// combinePages numbers a locked page followed by pages whose material has
+// all been cut with the range it now covers, e.g. when pages 14 and 15
+// are cut page 13 becomes 13-15. If the first pages are cut the first
+// page starts the range, e.g. 1-3.
+func combinePages(pages []*Page, first int) {
+	for i, page := range pages {
+		from, to := pageRange(page.Number)
+		if from < 0 {
+			continue
+		}
+		start, end := from, to
+		if i == 0 && start > first {
+			start = first
+		}
+		if i+1 < len(pages) {
+			if next, _ := pageRange(pages[i+1].Number); next > end+1 {
+				end = next - 1
+			}
+		}
+		if start != from || end != to {
+			label := strings.SplitN(page.Number, "-", 2)[0]
+			if start != from {
+				label = strconv.Itoa(start)
+			}
+			page.Number = label + "-" + strconv.Itoa(end)
+		}
+	}
+}
+
+// Paginate lays out the screenplay (see Layout) and records the page
+// each paragraph starts on in PageNumber and the number of pages in
+// Info.PageCount.
+func (document *OpenScreenplay) Paginate() []*Page {
+	pages := document.Layout()
+	starts := map[*Para]string{}
+	for _, page := range pages {
+		for _, line := range page.Lines {
+			if _, ok := starts[line.Para]; !ok && line.Para != nil {
+				starts[line.Para] = page.Number
+			}
+		}
+	}
+	if document.Paragraphs != nil {
+		// Paragraphs that aren't printed are on the page of the next
+		// printed paragraph
+		pageNo := pages[len(pages)-1].Number
+		for i := len(document.Paragraphs.Para) - 1; i >= 0; i-- {
+			para := document.Paragraphs.Para[i]
+			if number, ok := starts[para]; ok {
+				pageNo = number
+			}
+			para.PageNumber = pageNo
+		}
+	}
+	if document.Info == nil {
+		document.Info = new(Info)
+	}
+	document.Info.PageCount = strconv.Itoa(len(pages))
+	return pages
+}
+
+// LockPages paginates the screenplay and locks the pages so revisions
+// only change the pages they are on, see Layout
+func (document *OpenScreenplay) LockPages() []*Page {
+	if document.Settings == nil {
+		document.Settings = new(Settings)
+	}
+	pages := document.Paginate()
+	document.Settings.SetFlag(PagesLockedFlag, true)
+	return pages
+}
+
+// UnlockPages unlocks the pages, the next Paginate numbers the pages
+// from pagenumber_start again
+func (document *OpenScreenplay) UnlockPages() {
+	if document.Settings == nil {
+		document.Settings = new(Settings)
+	}
+	document.Settings.SetFlag(PagesLockedFlag, false)
+}
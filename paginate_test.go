@@ -0,0 +1,277 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWrapRuns(t *testing.T) {
+	runs := []*Text{
+		{InnerText: "She is "},
+		{InnerText: "very very", Bold: BoldStyle},
+		{InnerText: " late.\nAgain."},
+	}
+	lines := wrapRuns(runs, 12)
+	expected := []string{"She is very", "very late.", "Again."}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines, got %d", len(expected), len(lines))
+	}
+	for i, line := range lines {
+		if s := (&Line{Text: line}).String(); s != expected[i] {
+			t.Errorf("line %d, expected %q, got %q", i, expected[i], s)
+		}
+	}
+	if len(lines[0]) != 2 || lines[0][1].InnerText != "very" || lines[0][1].Bold != BoldStyle {
+		t.Errorf("expected the bold run to be split, got %+v", lines[0])
+	}
+	if lines := wrapRuns([]*Text{{InnerText: "abcdefghij"}}, 4); len(lines) != 3 {
+		t.Errorf("expected a long word to be broken into 3 lines, got %d", len(lines))
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	document, err := ParseFile(filepath.Join("testdata", "sample-01.osf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages := document.Paginate()
+	if len(pages) != 1 || document.Info.PageCount != "1" {
+		t.Errorf("expected 1 page, got %d (page count %q)", len(pages), document.Info.PageCount)
+	}
+	for i, para := range document.Paragraphs.Para {
+		if para.PageNumber != "1" {
+			t.Errorf("paragraph %d, expected page 1, got %q", i, para.PageNumber)
+		}
+	}
+
+	para := func(style string, s string) *Para {
+		return &Para{Style: &Style{BaseStyleName: style}, Text: []*Text{{InnerText: s}}}
+	}
+	newDocument := func(paras ...*Para) *OpenScreenplay {
+		document := NewOpenScreenplay20()
+		document.Settings = new(Settings)
+		document.Settings.SetFlag(DialogueContinuesFlag, true)
+		document.Paragraphs = &Paragraphs{Para: paras}
+		return document
+	}
+	linesPerPage := new(Settings).LinesPerPage()
+	if linesPerPage != 53 {
+		t.Errorf("expected 53 lines per page on US Letter, got %d", linesPerPage)
+	}
+
+	// 25 actions fill 49 lines, a scene heading would be left at the
+	// bottom of the page so it moves to the next with its action.
+	paras := []*Para{}
+	for i := 0; i < 25; i++ {
+		paras = append(paras, para(ActionType, "Action."))
+	}
+	heading := para(SceneHeadingType, "int. office - day")
+	action := para(ActionType, "Work happens.")
+	paras = append(paras, heading, action)
+	document = newDocument(paras...)
+	pages = document.Paginate()
+	if len(pages) != 2 {
+		t.Fatalf("expected 2 pages, got %d", len(pages))
+	}
+	if heading.PageNumber != "2" || action.PageNumber != "2" || paras[24].PageNumber != "1" {
+		t.Errorf("expected the scene heading and action on page 2, got %q, %q", heading.PageNumber, action.PageNumber)
+	}
+	if s := pages[1].Lines[0].String(); s != "INT. OFFICE - DAY" {
+		t.Errorf("expected page 2 to start with the scene heading, got %q", s)
+	}
+	if document.Info.PageCount != "2" {
+		t.Errorf("expected page count 2, got %q", document.Info.PageCount)
+	}
+
+	// Long dialogue breaks across the page with (MORE) and (CONT'D)
+	paras = []*Para{}
+	for i := 0; i < 20; i++ {
+		paras = append(paras, para(ActionType, "Action."))
+	}
+	speech := strings.Repeat("Words and more words. ", 40)
+	paras = append(paras, para(CharacterType, "Ann"), para(DialogueType, speech))
+	document = newDocument(paras...)
+	pages = document.Paginate()
+	if len(pages) != 2 {
+		t.Fatalf("expected 2 pages, got %d", len(pages))
+	}
+	for i, page := range pages {
+		if len(page.Lines) > linesPerPage {
+			t.Errorf("page %d has %d lines", i+1, len(page.Lines))
+		}
+	}
+	last := pages[0].Lines[len(pages[0].Lines)-1]
+	if last.String() != "(MORE)" || last.Style != CharacterType {
+		t.Errorf("expected page 1 to end with (MORE), got %q", last.String())
+	}
+	if s := pages[1].Lines[0].String(); s != "ANN (CONT'D)" {
+		t.Errorf("expected page 2 to start with %q, got %q", "ANN (CONT'D)", s)
+	}
+
+	// Dialogue continues when the same character speaks again in a scene
+	document = newDocument(
+		para(SceneHeadingType, "INT. OFFICE - DAY"),
+		para(CharacterType, "Ann"), para(DialogueType, "Hello."),
+		para(ActionType, "She waits."),
+		para(CharacterType, "Ann"), para(DialogueType, "Hello?"),
+		para(SceneHeadingType, "EXT. STREET - DAY"),
+		para(CharacterType, "Ann"), para(DialogueType, "Hello!"),
+	)
+	characters := []string{}
+	for _, line := range document.Layout()[0].Lines {
+		if line.Style == CharacterType {
+			characters = append(characters, line.String())
+		}
+	}
+	if s := strings.Join(characters, ", "); s != "ANN, ANN (CONT'D), ANN" {
+		t.Errorf("unexpected characters %q", s)
+	}
+
+	// A page break starts a new page
+	action = para(ActionType, "Later.")
+	action.Style.PageBreakBefore = "1"
+	document = newDocument(para(ActionType, "Now."), action)
+	if pages := document.Paginate(); len(pages) != 2 || action.PageNumber != "2" {
+		t.Errorf("expected the page break to start page 2, got %d pages", len(pages))
+	}
+}
+
+func TestLockedPages(t *testing.T) {
+	numbers := func(pages []*Page) string {
+		src := []string{}
+		for _, page := range pages {
+			src = append(src, page.Number)
+		}
+		return strings.Join(src, " ")
+	}
+	actions := func(n int, s string) []*Para {
+		paras := []*Para{}
+		for i := 0; i < n; i++ {
+			paras = append(paras, &Para{Style: &Style{BaseStyleName: ActionType}, Text: []*Text{{InnerText: fmt.Sprintf("%s %d.", s, i+1)}}})
+		}
+		return paras
+	}
+	newDocument := func() *OpenScreenplay {
+		// 27 actions fit on a page
+		document := NewOpenScreenplay20()
+		document.Settings = new(Settings)
+		document.Paragraphs = &Paragraphs{Para: actions(60, "Action")}
+		return document
+	}
+
+	document := newDocument()
+	pages := document.LockPages()
+	if got := numbers(pages); got != "1 2 3" || !document.Settings.Flag(PagesLockedFlag) {
+		t.Fatalf("expected locked pages 1 2 3, got %q", got)
+	}
+	paras := document.Paragraphs.Para
+	if paras[26].PageNumber != "1" || paras[27].PageNumber != "2" {
+		t.Errorf("expected paragraphs 27 and 28 on pages 1 and 2, got %q and %q", paras[26].PageNumber, paras[27].PageNumber)
+	}
+
+	// New material on page 1 continues on 1A, page 2 is unchanged
+	inserted := actions(10, "New")
+	document.Paragraphs.Para = append(append(append([]*Para{}, paras[:5]...), inserted...), paras[5:]...)
+	pages = document.Paginate()
+	if got := numbers(pages); got != "1 1A 2 3" {
+		t.Fatalf("expected pages 1 1A 2 3, got %q", got)
+	}
+	if pages[2].Lines[0].Para != paras[27] || len(pages[2].Lines) != len(document.Layout()[2].Lines) {
+		t.Errorf("expected page 2 to be unchanged")
+	}
+	if inserted[0].PageNumber != "1" || paras[26].PageNumber != "1A" || paras[27].PageNumber != "2" {
+		t.Errorf("expected the end of page 1 on 1A, got %q, %q, %q", inserted[0].PageNumber, paras[26].PageNumber, paras[27].PageNumber)
+	}
+	// A pages are locked too, more material on 1A makes 1B and more
+	// on page 1 goes between 1 and 1A
+	i := len(paras[:5]) + len(inserted) + 22
+	if document.Paragraphs.Para[i] != paras[27] {
+		t.Fatalf("expected paragraph 28 at %d", i)
+	}
+	document.Paragraphs.Para = append(append(append([]*Para{}, document.Paragraphs.Para[:i]...), actions(30, "More")...), paras[27:]...)
+	if got := numbers(document.Layout()); got != "1 1A 1B 2 3" {
+		t.Errorf("expected pages 1 1A 1B 2 3, got %q", got)
+	}
+	document.Paragraphs.Para = append(actions(30, "Before"), document.Paragraphs.Para...)
+	if got := numbers(document.Layout()); got != "1 1AA 1AB 1A 1B 2 3" {
+		t.Errorf("expected pages 1 1AA 1AB 1A 1B 2 3, got %q", got)
+	}
+	document.Paragraphs.Para = document.Paragraphs.Para[30:]
+	document.Settings.SetPageMode(NumberingModePrefix)
+	for _, para := range document.Paragraphs.Para {
+		para.PageNumber = strings.Replace(para.PageNumber, "1A", "A1", 1)
+	}
+	if got := numbers(document.Layout()); got != "1 A1 B1 2 3" {
+		t.Errorf("expected pages 1 A1 B1 2 3, got %q", got)
+	}
+
+	// Cutting all of page 2 combines it with page 1, then cutting page 1
+	document = newDocument()
+	document.LockPages()
+	paras = document.Paragraphs.Para
+	document.Paragraphs.Para = append(append([]*Para{}, paras[:27]...), paras[54:]...)
+	if got := numbers(document.Paginate()); got != "1-2 3" || paras[0].PageNumber != "1-2" {
+		t.Errorf("expected pages 1-2 3, got %q", got)
+	}
+	document.Paragraphs.Para = paras[54:]
+	if got := numbers(document.Layout()); got != "1-3" {
+		t.Errorf("expected page 1-3, got %q", got)
+	}
+	document.Paragraphs.Para = append(paras[:27], actions(20, "After")...)
+	if got := numbers(document.Layout()); got != "1-2 2A" {
+		t.Errorf("expected pages 1-2 2A, got %q", got)
+	}
+
+	// A paragraph split across locked pages still continues on the next
+	document = newDocument()
+	long := strings.Repeat("A long action that goes on and on. ", 30)
+	document.Paragraphs.Para[20].Text[0].InnerText = long
+	unlocked := document.LockPages()
+	locked := document.Layout()
+	if numbers(unlocked) != numbers(locked) || len(unlocked) != 3 {
+		t.Fatalf("expected the same pages once locked, got %q and %q", numbers(unlocked), numbers(locked))
+	}
+	for i := range unlocked {
+		if len(unlocked[i].Lines) != len(locked[i].Lines) {
+			t.Errorf("page %s, expected %d lines, got %d", unlocked[i].Number, len(unlocked[i].Lines), len(locked[i].Lines))
+		}
+	}
+
+	// Unlocking numbers the pages again
+	document.UnlockPages()
+	document.Paragraphs.Para = append(actions(30, "More"), document.Paragraphs.Para...)
+	if got := numbers(document.Paginate()); got != "1 2 3 4" {
+		t.Errorf("expected pages 1 2 3 4, got %q", got)
+	}
+}
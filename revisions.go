@@ -0,0 +1,530 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// RevisionColorNames are the page colours of a production draft's
+// revisions, the original draft is White and the first revision Blue
+var RevisionColorNames = []string{"White", "Blue", "Pink", "Yellow", "Green", "Goldenrod", "Buff", "Salmon", "Cherry", "Tan"}
+
+// maxDiffCells limits the size of the table used to compare sequences
+const maxDiffCells = 4000000
+
+// diffMatches returns the index pairs of a longest common subsequence of
+// two sequences n and m long, equal compares their elements. Common
+// prefixes and suffixes are matched first, if what is left between them
+// is too large to compare it is left unmatched.
+func diffMatches(n, m int, equal func(i, j int) bool) [][2]int {
+	prefix := 0
+	for prefix < n && prefix < m && equal(prefix, prefix) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < n-prefix && suffix < m-prefix && equal(n-suffix-1, m-suffix-1) {
+		suffix++
+	}
+	matches := [][2]int{}
+	for i := 0; i < prefix; i++ {
+		matches = append(matches, [2]int{i, i})
+	}
+	// lengths[i][j] is the longest common subsequence from i and j on
+	rows, cols := n-prefix-suffix, m-prefix-suffix
+	if rows > 0 && cols > 0 && (rows+1)*(cols+1) <= maxDiffCells {
+		lengths := make([]int32, (rows+1)*(cols+1))
+		at := func(i, j int) int { return i*(cols+1) + j }
+		for i := rows - 1; i >= 0; i-- {
+			for j := cols - 1; j >= 0; j-- {
+				switch {
+				case equal(prefix+i, prefix+j):
+					lengths[at(i, j)] = lengths[at(i+1, j+1)] + 1
+				case lengths[at(i+1, j)] >= lengths[at(i, j+1)]:
+					lengths[at(i, j)] = lengths[at(i+1, j)]
+				default:
+					lengths[at(i, j)] = lengths[at(i, j+1)]
+				}
+			}
+		}
+		for i, j := 0, 0; i < rows && j < cols; {
+			switch {
+			case equal(prefix+i, prefix+j):
+				matches = append(matches, [2]int{prefix + i, prefix + j})
+				i, j = i+1, j+1
+			case lengths[at(i+1, j)] >= lengths[at(i, j+1)]:
+				i++
+			default:
+				j++
+			}
+		}
+	}
+	for i := suffix; i > 0; i-- {
+		matches = append(matches, [2]int{n - i, m - i})
+	}
+	return matches
+}
+
+// reviseText sets the revision of the characters of para flagged in
+// revised, runs are split where only some of their text is revised
+func reviseText(para *Para, revised []bool, revision string) {
+	texts := []*Text{}
+	offset := 0
+	for _, text := range para.Text {
+		r := []rune(text.InnerText)
+		if len(r) == 0 || text.Revision == revision {
+			texts = append(texts, text)
+			offset += len(r)
+			continue
+		}
+		for start := 0; start < len(r); {
+			flag := offset+start < len(revised) && revised[offset+start]
+			end := start + 1
+			for end < len(r) && (offset+end < len(revised) && revised[offset+end]) == flag {
+				end++
+			}
+			t := *text
+			t.InnerText = string(r[start:end])
+			if flag {
+				t.Revision = revision
+			}
+			texts = append(texts, &t)
+			start = end
+		}
+		offset += len(r)
+	}
+	para.Text = texts
+}
+
+// addMark marks the place text was removed from para
+func addMark(para *Para, at int, revision string) {
+	if para.Marks == nil {
+		para.Marks = new(Marks)
+	}
+	s := strconv.Itoa(at)
+	for _, mark := range para.Marks.Mark {
+		if mark.At == s && mark.Revision == revision {
+			return
+		}
+	}
+	para.Marks.Mark = append(para.Marks.Mark, &Mark{At: s, Revision: revision})
+}
+
+// paraStyleName returns the base style name of a paragraph
+func paraStyleName(para *Para) string {
+	if para.Style == nil {
+		return ""
+	}
+	return para.Style.BaseStyleName
+}
+
+// markParaChanges marks what has changed between two versions of a
+// paragraph, added characters are revised and removed ones leave a mark.
+// A paragraph that changed style is revised as a whole.
+func markParaChanges(previous, current *Para, revision string) {
+	src, dest := []rune(previous.PlainText()), []rune(current.PlainText())
+	revised := make([]bool, len(dest))
+	if paraStyleName(previous) != paraStyleName(current) {
+		for i := range revised {
+			revised[i] = true
+		}
+		reviseText(current, revised, revision)
+		return
+	}
+	matches := diffMatches(len(src), len(dest), func(i, j int) bool { return src[i] == dest[j] })
+	i, j := 0, 0
+	for _, match := range append(matches, [2]int{len(src), len(dest)}) {
+		if match[0] > i {
+			addMark(current, j, revision)
+		}
+		for ; j < match[1]; j++ {
+			revised[j] = true
+		}
+		i, j = match[0]+1, match[1]+1
+	}
+	if len(dest) > 0 {
+		reviseText(current, revised, revision)
+	}
+}
+
+// markParaRevisions compares two lists of paragraphs. Paragraphs are
+// matched by style and text, the unmatched ones between them are paired
+// by style (or text if only the style changed) and compared character by
+// character. New paragraphs are revised as a whole and removed ones leave
+// a mark where they were.
+func markParaRevisions(previous, current []*Para, revision string) {
+	key := func(para *Para) string {
+		return paraStyleName(para) + "\x00" + para.PlainText()
+	}
+	matches := diffMatches(len(previous), len(current), func(i, j int) bool {
+		return key(previous[i]) == key(current[j])
+	})
+	a, c := 0, 0
+	for _, match := range append(matches, [2]int{len(previous), len(current)}) {
+		b, d := match[0], match[1]
+		k := a
+		for j := c; j < d; j++ {
+			para := current[j]
+			paired := -1
+			for i := k; i < b && paired < 0; i++ {
+				if paraStyleName(previous[i]) == paraStyleName(para) || previous[i].PlainText() == para.PlainText() {
+					paired = i
+				}
+			}
+			if paired < 0 {
+				revised := make([]bool, len([]rune(para.PlainText())))
+				for i := range revised {
+					revised[i] = true
+				}
+				reviseText(para, revised, revision)
+				continue
+			}
+			if paired > k {
+				addMark(para, 0, revision)
+			}
+			markParaChanges(previous[paired], para, revision)
+			k = paired + 1
+		}
+		if k < b {
+			// Paragraphs removed at the end of the gap
+			switch {
+			case d > 0:
+				addMark(current[d-1], len([]rune(current[d-1].PlainText())), revision)
+			case d < len(current):
+				addMark(current[d], 0, revision)
+			}
+		}
+		a, c = b+1, d+1
+	}
+}
+
+// MarkRevisions compares a new draft of a screenplay with the previous
+// one and marks what has changed as revision in the new draft the way
+// Fade In does. Added or changed text is given the revision and a mark is
+// left where text was removed. The new draft's revision is set, revisions
+// are shown and the revision colours are filled in. If revision is zero
+// the revision after the latest of the two drafts is used.
+func MarkRevisions(previous, current *OpenScreenplay, revision int) error {
+	if previous == nil || current == nil {
+		return fmt.Errorf("two drafts are needed to mark revisions")
+	}
+	if revision < 0 {
+		return fmt.Errorf("invalid revision %d", revision)
+	}
+	if revision == 0 {
+		revision = previous.Settings.RevisionNumber()
+		if n := current.Settings.RevisionNumber(); n > revision {
+			revision = n
+		}
+		revision++
+	}
+	s := strconv.Itoa(revision)
+	paras := func(document *OpenScreenplay) ([]*Para, []*Para) {
+		titlePage, body := []*Para{}, []*Para{}
+		if document.TitlePage != nil {
+			titlePage = document.TitlePage.Para
+		}
+		if document.Paragraphs != nil {
+			body = document.Paragraphs.Para
+		}
+		return titlePage, body
+	}
+	previousTitlePage, previousBody := paras(previous)
+	currentTitlePage, currentBody := paras(current)
+	markParaRevisions(previousTitlePage, currentTitlePage, s)
+	markParaRevisions(previousBody, currentBody, s)
+
+	if current.Settings == nil {
+		current.Settings = new(Settings)
+	}
+	current.Settings.SetRevisionNumber(revision)
+	current.Settings.SetFlag(ShowRevisionsFlag, true)
+	if current.Lists == nil {
+		current.Lists = new(Lists)
+	}
+	if current.Lists.RevisionColors == nil {
+		current.Lists.RevisionColors = new(RevisionColors)
+	}
+	colors := current.Lists.RevisionColors
+	for i := len(colors.RevisionColor); i <= revision; i++ {
+		name := RevisionColorNames[i%len(RevisionColorNames)]
+		index := strconv.Itoa(i % len(RevisionColorNames))
+		colors.RevisionColor = append(colors.RevisionColor, &RevisionColor{
+			Name:       name,
+			Index:      strconv.Itoa(i),
+			ColorName:  name,
+			ColorIndex: index,
+		})
+	}
+	return nil
+}
+
+// RevisionChanges lists the pages and scenes a revision changed
+type RevisionChanges struct {
+	Revision int    `json:"revision" yaml:"revision"`
+	Color    string `json:"color" yaml:"color"`
+	// Pages are the numbers of the pages with changes
+	Pages []string `json:"pages" yaml:"pages"`
+	// Scenes are the changed scenes' numbers, or their scene headings if
+	// they aren't numbered
+	Scenes []string `json:"scenes" yaml:"scenes"`
+}
+
+// revisionOf parses a revision attribute, it is zero if not revised
+func revisionOf(s string) int {
+	if revision := intOrDefault(s, 0); revision > 0 {
+		return revision
+	}
+	return 0
+}
+
+// paraRevisions returns the revisions of a paragraph's text and marks
+// between the character offsets start and end
+func paraRevisions(para *Para, start int, end int) map[int]bool {
+	revisions := map[int]bool{}
+	offset := 0
+	for _, text := range para.Text {
+		n := utf8.RuneCountInString(text.InnerText)
+		if revision := revisionOf(text.Revision); revision > 0 && offset < end && offset+n > start {
+			revisions[revision] = true
+		}
+		offset += n
+	}
+	if para.Marks != nil {
+		for _, mark := range para.Marks.Mark {
+			if at := intOrDefault(mark.At, -1); at >= start && at <= end && revisionOf(mark.Revision) > 0 {
+				revisions[revisionOf(mark.Revision)] = true
+			}
+		}
+	}
+	return revisions
+}
+
+// Revision returns the latest revision of the text on the line, it is
+// zero if the line hasn't been revised. (MORE) and (CONT'D) lines are
+// never revised.
+func (line *Line) Revision() int {
+	latest := 0
+	if line.Para != nil && line.Start >= 0 {
+		end := line.Start + utf8.RuneCountInString(line.String())
+		for revision := range paraRevisions(line.Para, line.Start, end) {
+			if revision > latest {
+				latest = revision
+			}
+		}
+	}
+	return latest
+}
+
+// RevisionColorName returns the colour of a revision, from the document's
+// revision colours if it has them
+func (document *OpenScreenplay) RevisionColorName(revision int) string {
+	if document.Lists != nil && document.Lists.RevisionColors != nil {
+		for i, color := range document.Lists.RevisionColors.RevisionColor {
+			if intOrDefault(color.Index, i) == revision {
+				if color.ColorName != "" {
+					return color.ColorName
+				}
+				return color.Name
+			}
+		}
+	}
+	if revision < 0 {
+		return ""
+	}
+	return RevisionColorNames[revision%len(RevisionColorNames)]
+}
+
+// allParas returns the title page and script paragraphs
+func (document *OpenScreenplay) allParas() []*Para {
+	paras := []*Para{}
+	if document.TitlePage != nil {
+		paras = append(paras, document.TitlePage.Para...)
+	}
+	if document.Paragraphs != nil {
+		paras = append(paras, document.Paragraphs.Para...)
+	}
+	return paras
+}
+
+// Revisions returns the revisions marked in the screenplay in order
+func (document *OpenScreenplay) Revisions() []int {
+	seen := map[int]bool{}
+	for _, para := range document.allParas() {
+		for revision := range paraRevisions(para, 0, utf8.RuneCountInString(para.PlainText())) {
+			seen[revision] = true
+		}
+	}
+	revisions := []int{}
+	for revision := range seen {
+		revisions = append(revisions, revision)
+	}
+	sort.Ints(revisions)
+	return revisions
+}
+
+// mergeRuns joins neighbouring runs of text with the same formatting
+func mergeRuns(para *Para) {
+	texts := []*Text{}
+	for _, text := range para.Text {
+		if n := len(texts); n > 0 && sameFormat(texts[n-1], text) {
+			merged := *texts[n-1]
+			merged.InnerText += text.InnerText
+			texts[n-1] = &merged
+			continue
+		}
+		texts = append(texts, text)
+	}
+	para.Text = texts
+}
+
+// sameFormat reports if two runs of text are formatted the same way and
+// have the same revision
+func sameFormat(a, b *Text) bool {
+	return a.Underline == b.Underline && a.Italic == b.Italic && a.Bold == b.Bold &&
+		a.Strikethrough == b.Strikethrough && a.AllCaps == b.AllCaps && a.Revision == b.Revision &&
+		len(a.UnknownAttrs) == 0 && len(b.UnknownAttrs) == 0 && len(a.UnknownElements) == 0 && len(b.UnknownElements) == 0
+}
+
+// KeepRevisions removes the revisions before revision, their text is no
+// longer revised and their marks are removed. Runs of text left with the
+// same formatting are joined.
+func (document *OpenScreenplay) KeepRevisions(revision int) {
+	for _, para := range document.allParas() {
+		changed := false
+		for _, text := range para.Text {
+			if text.Revision != "" && revisionOf(text.Revision) < revision {
+				text.Revision = ""
+				changed = true
+			}
+		}
+		if changed {
+			mergeRuns(para)
+		}
+		if para.Marks != nil {
+			marks := []*Mark{}
+			for _, mark := range para.Marks.Mark {
+				if revisionOf(mark.Revision) >= revision && revisionOf(mark.Revision) > 0 {
+					marks = append(marks, mark)
+				}
+			}
+			para.Marks.Mark = marks
+			if len(marks) == 0 && len(para.Marks.UnknownAttrs) == 0 && len(para.Marks.UnknownElements) == 0 {
+				para.Marks = nil
+			}
+		}
+	}
+}
+
+// ClearRevisions removes all the revisions to start a new draft, the
+// revision is set back to zero
+func (document *OpenScreenplay) ClearRevisions() {
+	document.KeepRevisions(math.MaxInt)
+	if document.Settings != nil && document.Settings.Revision != "" {
+		document.Settings.SetRevisionNumber(0)
+	}
+}
+
+// RevisedPages returns the laid out pages (see Layout) with changes from
+// revision on
+func (document *OpenScreenplay) RevisedPages(revision int) []*Page {
+	pages := []*Page{}
+	for _, page := range document.Layout() {
+		for _, line := range page.Lines {
+			if latest := line.Revision(); latest > 0 && latest >= revision {
+				pages = append(pages, page)
+				break
+			}
+		}
+	}
+	return pages
+}
+
+// RevisionChanges lists the pages and scenes each revision changed
+func (document *OpenScreenplay) RevisionChanges() []*RevisionChanges {
+	changes := map[int]*RevisionChanges{}
+	changesOf := func(revision int) *RevisionChanges {
+		if _, ok := changes[revision]; !ok {
+			changes[revision] = &RevisionChanges{
+				Revision: revision,
+				Color:    document.RevisionColorName(revision),
+				Pages:    []string{},
+				Scenes:   []string{},
+			}
+		}
+		return changes[revision]
+	}
+	for _, page := range document.Layout() {
+		seen := map[int]bool{}
+		for _, line := range page.Lines {
+			if line.Para == nil || line.Start < 0 {
+				continue
+			}
+			for revision := range paraRevisions(line.Para, line.Start, line.Start+utf8.RuneCountInString(line.String())) {
+				if !seen[revision] {
+					seen[revision] = true
+					c := changesOf(revision)
+					c.Pages = append(c.Pages, page.Number)
+				}
+			}
+		}
+	}
+	if document.Paragraphs != nil {
+		sceneNumbers := document.shownSceneNumbers()
+		scene := ""
+		seen := map[int]bool{}
+		for _, para := range document.Paragraphs.Para {
+			if para.isSceneHeading() {
+				scene, seen = sceneNumbers[para], map[int]bool{}
+				if scene == "" {
+					scene = strings.ToUpper(strings.TrimSpace(para.PlainText()))
+				}
+			}
+			for revision := range paraRevisions(para, 0, utf8.RuneCountInString(para.PlainText())) {
+				if scene != "" && !seen[revision] {
+					seen[revision] = true
+					c := changesOf(revision)
+					c.Scenes = append(c.Scenes, scene)
+				}
+			}
+		}
+	}
+	list := []*RevisionChanges{}
+	for _, c := range changes {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Revision < list[j].Revision })
+	return list
+}
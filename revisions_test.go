@@ -0,0 +1,244 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// revisedText renders a paragraph's text with revised runs in braces and
+// marks as a "|" followed by their revision
+func revisedText(para *Para) string {
+	var sb strings.Builder
+	marks := map[string][]string{}
+	if para.Marks != nil {
+		for _, mark := range para.Marks.Mark {
+			marks[mark.At] = append(marks[mark.At], mark.Revision)
+		}
+	}
+	offset := 0
+	writeMarks := func() {
+		for _, revision := range marks[fmt.Sprintf("%d", offset)] {
+			sb.WriteString("|" + revision)
+		}
+		delete(marks, fmt.Sprintf("%d", offset))
+	}
+	for _, text := range para.Text {
+		for _, r := range text.InnerText {
+			writeMarks()
+			if text.Revision != "" {
+				fmt.Fprintf(&sb, "{%c}", r)
+			} else {
+				sb.WriteRune(r)
+			}
+			offset++
+		}
+	}
+	writeMarks()
+	return strings.ReplaceAll(sb.String(), "}{", "")
+}
+
+func TestDiffMatches(t *testing.T) {
+	for _, test := range []struct {
+		a, b     string
+		expected string
+	}{
+		{"", "", ""},
+		{"abc", "abc", "aa bb cc"},
+		{"abcd", "axcd", "aa cc dd"},
+		{"kitten", "sitting", "ii tt tt nn"},
+		{"abc", "", ""},
+	} {
+		matches := diffMatches(len(test.a), len(test.b), func(i, j int) bool { return test.a[i] == test.b[j] })
+		src := []string{}
+		for _, match := range matches {
+			src = append(src, string([]byte{test.a[match[0]], test.b[match[1]]}))
+		}
+		if got := strings.Join(src, " "); got != test.expected {
+			t.Errorf("%q and %q, expected %q, got %q", test.a, test.b, test.expected, got)
+		}
+	}
+}
+
+func TestMarkRevisions(t *testing.T) {
+	fname := filepath.Join("testdata", "sample-01.osf")
+	previous, err := ParseFile(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, _ := ParseFile(fname)
+	if err := MarkRevisions(previous, current, 0); err != nil {
+		t.Fatal(err)
+	}
+	for i, para := range current.Paragraphs.Para {
+		if s := revisedText(para); s != para.PlainText() {
+			t.Errorf("paragraph %d, expected no revisions, got %q", i, s)
+		}
+	}
+
+	current, _ = ParseFile(fname)
+	paras := current.Paragraphs.Para
+	paras[2].Text = []*Text{{InnerText: "A tired PROGRAMMER typing at a laptop"}}
+	paras[5].Text = append(paras[5].Text, &Text{Bold: BoldStyle, InnerText: " It works!"})
+	paras[6].Style.BaseStyleName = ActionType
+	explodes := &Para{Style: &Style{BaseStyleName: ActionType}, Text: []*Text{{InnerText: "The laptop explodes."}}}
+	current.Paragraphs.Para = append(append(append([]*Para{}, paras[:4]...), paras[5], explodes), paras[6:]...)
+	if err := MarkRevisions(previous, current, 0); err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []string{
+		"Fade in:",
+		"Ext. Library - day",
+		"A {tired }PROGRAMMER typing at a|1 laptop",
+		"Programmer",
+		"|1Eureka!{ It works!}",
+		"{The laptop explodes.}",
+		"{Fade to black.}",
+	} {
+		if got := revisedText(current.Paragraphs.Para[i]); got != expected {
+			t.Errorf("paragraph %d, expected %q, got %q", i, expected, got)
+		}
+	}
+	if text := current.Paragraphs.Para[4].Text; text[0].Bold != "" || text[1].Bold != BoldStyle || text[1].Revision != "1" {
+		t.Errorf("expected the formatting kept on revised text")
+	}
+	settings := current.Settings
+	if settings.RevisionNumber() != 1 || !settings.Flag(ShowRevisionsFlag) {
+		t.Errorf("expected revision 1 shown, got %q", settings.Revision)
+	}
+	if colors := current.Lists.RevisionColors.RevisionColor; len(colors) < 2 || colors[1].Name != "Blue" {
+		t.Errorf("expected Blue for revision 1")
+	}
+
+	// The next revision keeps the first's marks
+	next, _ := Parse(mustXML(t, current))
+	next.Paragraphs.Para[0].Text[0].InnerText = "Fade in slowly:"
+	if err := MarkRevisions(current, next, 0); err != nil {
+		t.Fatal(err)
+	}
+	if s := revisedText(next.Paragraphs.Para[0]); s != "Fade in{ slowly}:" || next.Paragraphs.Para[0].Text[1].Revision != "2" {
+		t.Errorf("expected revision 2, got %q", s)
+	}
+	if s := revisedText(next.Paragraphs.Para[2]); s != "A {tired }PROGRAMMER typing at a|1 laptop" {
+		t.Errorf("expected revision 1 to be kept, got %q", s)
+	}
+	if colors := next.Lists.RevisionColors.RevisionColor; next.Settings.Revision != "2" || colors[2].Name != "Pink" {
+		t.Errorf("expected revision 2 in Pink, got %q", next.Settings.Revision)
+	}
+	if err := MarkRevisions(nil, next, 3); err == nil {
+		t.Errorf("expected an error without a previous draft")
+	}
+}
+
+// mustXML renders document as XML
+func mustXML(t *testing.T, document *OpenScreenplay) []byte {
+	t.Helper()
+	src, err := document.ToXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+func TestRevisionCollation(t *testing.T) {
+	document, err := ParseFile(filepath.Join("testdata", "OSF-2.0.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(document.Revisions()); got != "[1 2]" {
+		t.Errorf("expected revisions [1 2], got %s", got)
+	}
+	if document.RevisionColorName(1) != "Blue" || document.RevisionColorName(2) != "Pink" || NewOpenScreenplay20().RevisionColorName(3) != "Yellow" {
+		t.Errorf("unexpected revision colours")
+	}
+	changes := document.RevisionChanges()
+	if len(changes) != 2 {
+		t.Fatalf("expected changes for 2 revisions, got %d", len(changes))
+	}
+	for i, expected := range []string{
+		"1 Blue pages [2] scenes [A1]",
+		"2 Pink pages [1 2 2A] scenes [A1 1]",
+	} {
+		c := changes[i]
+		if got := fmt.Sprintf("%d %s pages %v scenes %v", c.Revision, c.Color, c.Pages, c.Scenes); got != expected {
+			t.Errorf("expected %q, got %q", expected, got)
+		}
+	}
+	revised := 0
+	for _, page := range document.Layout() {
+		for _, line := range page.Lines {
+			if line.Revision() > 0 {
+				revised++
+			}
+		}
+	}
+	if pages := document.RevisedPages(2); len(pages) != 3 || revised == 0 {
+		t.Errorf("expected 3 pages revised in Pink, got %d", len(pages))
+	}
+	if s := document.ToRevisedText(1); strings.Count(s, "\f") != 2 || strings.Contains(s, "Open Screenplay Format\n") {
+		t.Errorf("expected the 3 revised pages without the title page\n%s", s)
+	}
+
+	// Keep the Pink revisions
+	document.KeepRevisions(2)
+	if got := fmt.Sprint(document.Revisions()); got != "[2]" {
+		t.Errorf("expected revisions [2], got %s", got)
+	}
+	for _, para := range document.Paragraphs.Para {
+		if para.PlainText() == "An element completely marked as a Blue revision." && (len(para.Text) != 1 || para.Text[0].Revision != "") {
+			t.Errorf("expected the Blue revision to be removed")
+		}
+	}
+	if pages := document.RevisedPages(1); len(pages) != 3 {
+		t.Errorf("expected 3 revised pages, got %d", len(pages))
+	}
+
+	// A new draft
+	document.ClearRevisions()
+	if got := len(document.Revisions()); got != 0 || document.Settings.RevisionNumber() != 0 {
+		t.Errorf("expected no revisions, got %d in revision %q", got, document.Settings.Revision)
+	}
+	for _, para := range document.allParas() {
+		if para.Marks != nil {
+			t.Errorf("expected no marks on %q", para.PlainText())
+		}
+	}
+	if pages := document.RevisedPages(1); len(pages) != 0 || len(document.RevisionChanges()) != 0 {
+		t.Errorf("expected no revised pages, got %d", len(pages))
+	}
+	var buf bytes.Buffer
+	if err := document.ToRevisedPDF(&buf, 1); err == nil || buf.Len() > 0 {
+		t.Errorf("expected an error and no PDF without revised pages, got %d bytes", buf.Len())
+	}
+}
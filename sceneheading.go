@@ -0,0 +1,189 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultSceneIntros are the scene intros recognized in scene headings
+// when a document doesn't list its own
+var DefaultSceneIntros = []string{"INT.", "EXT.", "INT./EXT.", "EXT./INT.", "I/E.", "EST."}
+
+// DefaultSceneTimes are the times of day recognized in scene headings
+// when a document doesn't list its own
+var DefaultSceneTimes = []string{"DAY", "NIGHT", "MORNING", "AFTERNOON", "EVENING", "LATER", "MOMENTS LATER", "CONTINUOUS", "THE NEXT DAY"}
+
+// SceneHeading is a scene heading split into its parts, e.g.
+// "INT. HOUSE - KITCHEN - NIGHT" has the intro "INT.", the location
+// "HOUSE", the sub-location "KITCHEN" and the time "NIGHT". Intros and
+// times are given as they are listed in the vocabulary, locations as
+// they are written.
+type SceneHeading struct {
+	Intro       string `json:"intro,omitempty" yaml:"intro,omitempty"`
+	Location    string `json:"location,omitempty" yaml:"location,omitempty"`
+	SubLocation string `json:"sub_location,omitempty" yaml:"sub_location,omitempty"`
+	Time        string `json:"time,omitempty" yaml:"time,omitempty"`
+	// separator is the separator the heading was parsed with
+	separator string
+}
+
+// prefixFold returns the length of prefix if s starts with it ignoring
+// case and it isn't followed by a letter or digit, "INT" is a prefix of
+// "INT HOUSE" but not "INTERCUT"
+func prefixFold(s string, prefix string) int {
+	if prefix == "" || len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return 0
+	}
+	if r, _ := utf8.DecodeRuneInString(s[len(prefix):]); unicode.IsLetter(r) || unicode.IsDigit(r) {
+		return 0
+	}
+	return len(prefix)
+}
+
+// longestFirst returns the non-blank names sorted longest first so
+// "INT./EXT." is tried before "INT."
+func longestFirst(names []string) []string {
+	sorted := []string{}
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			sorted = append(sorted, name)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	return sorted
+}
+
+// parseSceneHeading splits a scene heading using the intros, times and
+// locations of a vocabulary and the separator between its parts
+func parseSceneHeading(s string, intros, times, locations []string, separator string) *SceneHeading {
+	heading := &SceneHeading{separator: separator}
+	s = strings.Join(strings.Fields(s), " ")
+	// The intro, with or without its full stop
+	for _, intro := range longestFirst(intros) {
+		n := prefixFold(s, intro)
+		if n == 0 && strings.HasSuffix(intro, ".") {
+			n = prefixFold(s, strings.TrimSuffix(intro, "."))
+		}
+		if n > 0 {
+			heading.Intro = intro
+			s = strings.TrimSpace(s[n:])
+			break
+		}
+	}
+	// A listed location may include the separator, e.g. "HOUSE - KITCHEN"
+	for _, location := range longestFirst(locations) {
+		if n := prefixFold(s, location); n > 0 && (n == len(s) || strings.HasPrefix(s[n:], separator)) {
+			heading.Location = s[:n]
+			s = strings.TrimPrefix(s[n:], separator)
+			break
+		}
+	}
+	parts := []string{}
+	for _, part := range strings.Split(s, separator) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if heading.Location == "" && len(parts) > 0 {
+		heading.Location, parts = parts[0], parts[1:]
+	}
+	// The last part is the time if it is a listed time, or if it follows
+	// a sub-location
+	if n := len(parts); n > 0 {
+		for _, time := range longestFirst(times) {
+			if strings.EqualFold(parts[n-1], time) {
+				heading.Time = time
+				break
+			}
+		}
+		if heading.Time == "" && n > 1 {
+			heading.Time = parts[n-1]
+		}
+		if heading.Time != "" {
+			parts = parts[:n-1]
+		}
+	}
+	heading.SubLocation = strings.Join(parts, separator)
+	return heading
+}
+
+// ParseSceneHeading splits a scene heading into its intro, location,
+// sub-location and time of day using DefaultSceneIntros,
+// DefaultSceneTimes and " - " as the separator
+func ParseSceneHeading(s string) *SceneHeading {
+	return parseSceneHeading(s, DefaultSceneIntros, DefaultSceneTimes, nil, " - ")
+}
+
+// ParseSceneHeading splits a scene heading into its intro, location,
+// sub-location and time of day using the document's vocabulary, the
+// scene intros, scene times and locations in its lists (the defaults if
+// it doesn't list any intros or times) and its scene_time_separator
+func (document *OpenScreenplay) ParseSceneHeading(s string) *SceneHeading {
+	intros, times, locations := []string{}, []string{}, []string{}
+	if lists := document.Lists; lists != nil {
+		intros, times, locations = lists.SceneIntros.names(), lists.SceneTimes.names(), lists.Locations.names()
+	}
+	if len(intros) == 0 {
+		intros = DefaultSceneIntros
+	}
+	if len(times) == 0 {
+		times = DefaultSceneTimes
+	}
+	return parseSceneHeading(s, intros, times, locations, document.Settings.TimeSeparator())
+}
+
+// Place returns the location and sub-location, e.g. "HOUSE - KITCHEN"
+func (heading *SceneHeading) Place() string {
+	separator := heading.separator
+	if separator == "" {
+		separator = " - "
+	}
+	if heading.SubLocation == "" {
+		return heading.Location
+	}
+	return heading.Location + separator + heading.SubLocation
+}
+
+// String returns the scene heading put back together
+func (heading *SceneHeading) String() string {
+	separator := heading.separator
+	if separator == "" {
+		separator = " - "
+	}
+	s := strings.TrimSpace(heading.Intro + " " + heading.Place())
+	if heading.Time != "" {
+		s += separator + heading.Time
+	}
+	return s
+}
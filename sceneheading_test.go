@@ -0,0 +1,73 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSceneHeading(t *testing.T) {
+	for s, expected := range map[string]SceneHeading{
+		"INT. HOUSE - KITCHEN - NIGHT":      {Intro: "INT.", Location: "HOUSE", SubLocation: "KITCHEN", Time: "NIGHT"},
+		"int./ext. car - moments later":     {Intro: "INT./EXT.", Location: "car", Time: "MOMENTS LATER"},
+		"EXT GARDEN":                        {Intro: "EXT.", Location: "GARDEN"},
+		"EXT. MARY-JANE'S HOUSE - DAY":      {Intro: "EXT.", Location: "MARY-JANE'S HOUSE", Time: "DAY"},
+		"INT. HOUSE - HALL - STAIRS - DAWN": {Intro: "INT.", Location: "HOUSE", SubLocation: "HALL - STAIRS", Time: "DAWN"},
+		"INT. HOUSE - HALL":                 {Intro: "INT.", Location: "HOUSE", SubLocation: "HALL"},
+		"INTERCUT PHONE CALL":               {Location: "INTERCUT PHONE CALL"},
+	} {
+		heading := ParseSceneHeading(s)
+		if heading.Intro != expected.Intro || heading.Location != expected.Location || heading.SubLocation != expected.SubLocation || heading.Time != expected.Time {
+			t.Errorf("%q, expected %+v, got %+v", s, expected, *heading)
+		}
+	}
+	if s := ParseSceneHeading("int. house - kitchen - day").String(); s != "INT. house - kitchen - DAY" {
+		t.Errorf("unexpected scene heading %q", s)
+	}
+
+	// The document's own vocabulary and separator
+	document, err := ParseFile(filepath.Join("testdata", "OSF-2.0.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if heading := document.ParseSceneHeading("Int. first location - the next day"); heading.Intro != "INT." || heading.Location != "first location" || heading.Time != "THE NEXT DAY" {
+		t.Errorf("unexpected scene heading %+v", *heading)
+	}
+	document.Settings.SceneTimeSeparator = ", "
+	document.Lists.Locations.Location = append(document.Lists.Locations.Location, &Location{Name: "HOUSE, KITCHEN"})
+	if heading := document.ParseSceneHeading("INT. HOUSE, KITCHEN, SINK, NIGHT"); heading.Location != "HOUSE, KITCHEN" || heading.SubLocation != "SINK" || heading.Time != "NIGHT" {
+		t.Errorf("unexpected scene heading %+v", *heading)
+	}
+	scenes := document.Scenes()
+	if len(scenes) < 2 || scenes[1].HeadingParts == nil || scenes[1].Location() != "SCENE HEADING" {
+		t.Errorf("expected the scenes' headings to be parsed")
+	}
+}
@@ -0,0 +1,205 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// lockedNumberRE splits a scene or page number into its prefix letters,
+// number and suffix letters, e.g. "A12" or "12B"
+var lockedNumberRE = regexp.MustCompile(`^([A-Z]*)([0-9]+)([A-Z]*)$`)
+
+// isSceneHeading reports if para is a scene heading
+func (para *Para) isSceneHeading() bool {
+	return para != nil && para.Style != nil && para.Style.BaseStyleName == SceneHeadingType
+}
+
+// nextLetters returns the letters following s, "A" follows "", "B"
+// follows "A" and "AA" follows "Z". I and O are skipped if skipIO is true
+// so they aren't mistaken for 1 and 0.
+func nextLetters(s string, skipIO bool) string {
+	if s == "" {
+		return "A"
+	}
+	last := s[len(s)-1] + 1
+	if skipIO && (last == 'I' || last == 'O') {
+		last++
+	}
+	if last > 'Z' {
+		return nextLetters(s[:len(s)-1], skipIO) + "A"
+	}
+	return s[:len(s)-1] + string(last)
+}
+
+// insertedNumber numbers a scene or page inserted after the one numbered
+// prev, e.g. 12 gives 12A (or A12 in the A1 mode) and 12A gives 12B.
+// If that number is already used (it is inserted between 12 and 12A)
+// letters are added until it isn't, e.g. 12AA, then 12AB follows 12AA.
+func insertedNumber(prev string, mode NumberingMode, skipIO bool, used map[string]bool) string {
+	number, letters := prev, ""
+	if m := lockedNumberRE.FindStringSubmatch(strings.ToUpper(prev)); m != nil {
+		number, letters = m[2], m[1]+m[3]
+	}
+	format := func(letters string) string {
+		if mode == NumberingModePrefix {
+			return letters + number
+		}
+		return number + letters
+	}
+	s := format(nextLetters(letters, skipIO))
+	for extra := letters + "A"; used[s]; extra += "A" {
+		s = format(extra)
+	}
+	return s
+}
+
+// SceneNumbers works out the number of each scene heading. Scenes are
+// numbered from scenenumber_start unless scenes are locked, then scene
+// headings keep their number and scenes inserted since are numbered from
+// the scene before them using the scenenumber_mode, e.g. 12A, 12B (1AB)
+// or A12, B12 (A1). Scenes inserted before the first numbered scene are
+// A1, B1 in the 1AB mode and follow scene 0 (A0, B0) in the A1 mode.
+// Locked scripts without any numbered scenes are numbered from the start.
+// Scene headings without text aren't numbered.
+func (document *OpenScreenplay) SceneNumbers() map[*Para]string {
+	numbers := map[*Para]string{}
+	if document.Paragraphs == nil {
+		return numbers
+	}
+	settings := document.Settings
+	first := ""
+	used := map[string]bool{}
+	for _, para := range document.Paragraphs.Para {
+		if para.isSceneHeading() && para.SceneNumber != "" {
+			if first == "" {
+				first = para.SceneNumber
+			}
+			used[strings.ToUpper(para.SceneNumber)] = true
+		}
+	}
+	if !settings.Flag(ScenesLockedFlag) || first == "" {
+		n := settings.FirstSceneNumber()
+		for _, para := range document.Paragraphs.Para {
+			if para.isSceneHeading() && para.isPrinted() {
+				numbers[para] = strconv.Itoa(n)
+				n++
+			}
+		}
+		return numbers
+	}
+	mode, skipIO := settings.SceneMode(), settings.Flag(SceneNumberSkipIOFlag)
+	prev, before := "", ""
+	for _, para := range document.Paragraphs.Para {
+		if !para.isSceneHeading() || !para.isPrinted() {
+			continue
+		}
+		number := para.SceneNumber
+		switch {
+		case number != "":
+		case prev == "" && mode != NumberingModePrefix:
+			// Before the first numbered scene, e.g. A1, B1
+			for number == "" || used[number] {
+				before = nextLetters(before, skipIO)
+				number = before + first
+			}
+		case prev == "":
+			number = insertedNumber("0", mode, skipIO, used)
+		default:
+			number = insertedNumber(prev, mode, skipIO, used)
+		}
+		used[number] = true
+		numbers[para] = number
+		if para.SceneNumber != "" || prev != "" || mode == NumberingModePrefix {
+			prev = number
+		}
+	}
+	return numbers
+}
+
+// NumberScenes stores the scene numbers (see SceneNumbers) in the scene
+// headings' SceneNumber and turns on scene_numbering
+func (document *OpenScreenplay) NumberScenes() {
+	for para, number := range document.SceneNumbers() {
+		para.SceneNumber = number
+	}
+	if document.Settings == nil {
+		document.Settings = new(Settings)
+	}
+	document.Settings.SetFlag(SceneNumberingFlag, true)
+}
+
+// LockScenes numbers the scenes and locks them so scenes added later are
+// numbered between them (e.g. 12A) rather than renumbering the script
+func (document *OpenScreenplay) LockScenes() {
+	document.NumberScenes()
+	document.Settings.SetFlag(ScenesLockedFlag, true)
+}
+
+// UnlockScenes unlocks the scene numbers, the next NumberScenes numbers
+// the scenes from scenenumber_start again
+func (document *OpenScreenplay) UnlockScenes() {
+	if document.Settings == nil {
+		document.Settings = new(Settings)
+	}
+	document.Settings.SetFlag(ScenesLockedFlag, false)
+}
+
+// shownSceneNumbers returns the scene numbers renderers and reports show.
+// They are the scene numbers (see SceneNumbers) when scene_numbering is
+// on, otherwise the numbers the scene headings already have unless
+// scene_numbering is turned off.
+func (document *OpenScreenplay) shownSceneNumbers() map[*Para]string {
+	settings := document.Settings
+	if settings.Flag(SceneNumberingFlag) {
+		return document.SceneNumbers()
+	}
+	numbers := map[*Para]string{}
+	if (settings == nil || strings.TrimSpace(settings.SceneNumbering) == "") && document.Paragraphs != nil {
+		for _, para := range document.Paragraphs.Para {
+			if para.isSceneHeading() && para.SceneNumber != "" {
+				numbers[para] = para.SceneNumber
+			}
+		}
+	}
+	return numbers
+}
+
+// printedSceneNumbers returns the shown scene numbers formatted with
+// scenenumber_format
+func (document *OpenScreenplay) printedSceneNumbers() map[*Para]string {
+	numbers := document.shownSceneNumbers()
+	for para, number := range numbers {
+		numbers[para] = document.Settings.FormatSceneNumber(number)
+	}
+	return numbers
+}
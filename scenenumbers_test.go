@@ -0,0 +1,160 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// sceneDocument returns a document with a scene heading for each of
+// numbers and an action between them
+func sceneDocument(numbers ...string) *OpenScreenplay {
+	document := NewOpenScreenplay20()
+	document.Settings = new(Settings)
+	document.Paragraphs = new(Paragraphs)
+	for _, number := range numbers {
+		document.Paragraphs.Para = append(document.Paragraphs.Para,
+			&Para{SceneNumber: number, Style: &Style{BaseStyleName: SceneHeadingType}, Text: []*Text{{InnerText: "INT. HOUSE - DAY"}}},
+			&Para{Style: &Style{BaseStyleName: ActionType}, Text: []*Text{{InnerText: "Something happens."}}})
+	}
+	return document
+}
+
+// sceneNumbersOf returns the scene numbers in document order
+func sceneNumbersOf(document *OpenScreenplay) string {
+	numbers := document.SceneNumbers()
+	src := []string{}
+	for _, para := range document.Paragraphs.Para {
+		if number, ok := numbers[para]; ok {
+			src = append(src, number)
+		}
+	}
+	return strings.Join(src, " ")
+}
+
+func TestSceneNumbers(t *testing.T) {
+	for s, expected := range map[string]string{
+		"":   "A",
+		"A":  "B",
+		"H":  "J",
+		"N":  "P",
+		"Z":  "AA",
+		"AZ": "BA",
+	} {
+		if got := nextLetters(s, true); got != expected {
+			t.Errorf("expected %q after %q, got %q", expected, s, got)
+		}
+	}
+	if got := nextLetters("H", false); got != "I" {
+		t.Errorf("expected I after H, got %q", got)
+	}
+
+	// Unlocked scenes are numbered from the start
+	document := sceneDocument("4", "", "9A")
+	if got := sceneNumbersOf(document); got != "1 2 3" {
+		t.Errorf("expected 1 2 3, got %q", got)
+	}
+	document.Settings.SetFirstSceneNumber(10)
+	if got := sceneNumbersOf(document); got != "10 11 12" {
+		t.Errorf("expected 10 11 12, got %q", got)
+	}
+
+	// Locked scenes keep their numbers
+	for _, test := range []struct {
+		numbers  []string
+		mode     NumberingMode
+		skipIO   bool
+		expected string
+	}{
+		{[]string{"1", "", "", "2", ""}, NumberingModeSuffix, false, "1 1A 1B 2 2A"},
+		{[]string{"1", "", "1A", "2"}, NumberingModeSuffix, false, "1 1AA 1A 2"},
+		{[]string{"1", "1H", "", "2"}, NumberingModeSuffix, true, "1 1H 1J 2"},
+		{[]string{"", "", "1", "2"}, NumberingModeSuffix, false, "A1 B1 1 2"},
+		{[]string{"12", "", "", "13"}, NumberingModePrefix, false, "12 A12 B12 13"},
+		{[]string{"", "1"}, NumberingModePrefix, false, "A0 1"},
+		{[]string{"", "", ""}, NumberingModeSuffix, false, "1 2 3"},
+	} {
+		document := sceneDocument(test.numbers...)
+		document.Settings.SetFlag(ScenesLockedFlag, true)
+		document.Settings.SetFlag(SceneNumberSkipIOFlag, test.skipIO)
+		document.Settings.SetSceneMode(test.mode)
+		if got := sceneNumbersOf(document); got != test.expected {
+			t.Errorf("expected %q for %q in %s mode, got %q", test.expected, test.numbers, test.mode, got)
+		}
+	}
+
+	// Locking, then inserting a scene
+	document = sceneDocument("", "", "")
+	document.LockScenes()
+	if !document.Settings.Flag(SceneNumberingFlag) || !document.Settings.Flag(ScenesLockedFlag) {
+		t.Errorf("expected scene numbering on and locked")
+	}
+	paras := document.Paragraphs.Para
+	if paras[0].SceneNumber != "1" || paras[2].SceneNumber != "2" || paras[4].SceneNumber != "3" {
+		t.Errorf("expected scenes 1, 2 and 3, got %q, %q and %q", paras[0].SceneNumber, paras[2].SceneNumber, paras[4].SceneNumber)
+	}
+	document.Paragraphs.Para = append(paras[0:2], append([]*Para{{Style: &Style{BaseStyleName: SceneHeadingType}, Text: []*Text{{InnerText: "EXT. GARDEN - DAY"}}}}, paras[2:]...)...)
+	document.NumberScenes()
+	if got := sceneNumbersOf(document); got != "1 1A 2 3" || document.Paragraphs.Para[2].SceneNumber != "1A" {
+		t.Errorf("expected 1 1A 2 3, got %q", got)
+	}
+	document.UnlockScenes()
+	document.NumberScenes()
+	if got := sceneNumbersOf(document); got != "1 2 3 4" || document.Paragraphs.Para[2].SceneNumber != "2" {
+		t.Errorf("expected 1 2 3 4, got %q", got)
+	}
+
+	// Renderers show the scene numbers
+	document = sceneDocument("", "")
+	if s := document.ToHTMLFragment(); strings.Contains(s, "data-scene-number") {
+		t.Errorf("expected no scene numbers before numbering\n%s", s)
+	}
+	document.NumberScenes()
+	document.Settings.SceneNumberFormat = "#."
+	if s := document.ToHTMLFragment(); !strings.Contains(s, `<p class="scene-heading" data-scene-number="2.">INT. HOUSE - DAY</p>`) {
+		t.Errorf("expected scene number 2. in\n%s", s)
+	}
+	if s := document.ToText(); !strings.Contains(s, "\n       2.   INT. HOUSE - DAY                                               2.\n") {
+		t.Errorf("expected scene number 2. in both margins\n%s", s)
+	}
+	var buf bytes.Buffer
+	if err := document.ToPDF(&buf); err != nil || !strings.Contains(buf.String(), "Td (2.) Tj ET\n") {
+		t.Errorf("expected scene number 2. in the PDF, %s", err)
+	}
+	if items := document.Outline(); len(items) != 2 || items[1].SceneNumber != "2" {
+		t.Errorf("expected scene 2 in the outline")
+	}
+	document.Settings.SetFlag(SceneNumberingFlag, false)
+	if s := document.ToText(); strings.Contains(s, "2.") {
+		t.Errorf("expected no scene numbers when scene numbering is off\n%s", s)
+	}
+}
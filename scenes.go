@@ -0,0 +1,284 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"regexp"
+	"strings"
+)
+
+// cueExtensionRE matches the extensions of a character cue, e.g. "(V.O.)"
+// or "(CONT'D)"
+var cueExtensionRE = regexp.MustCompile(`\([^)]*\)`)
+
+// Scene is a scene heading and the paragraphs up to the next one
+type Scene struct {
+	// Heading is the scene heading paragraph, it is nil for the
+	// paragraphs before the first scene heading
+	Heading *Para `json:"-" yaml:"-"`
+	// HeadingParts is the scene heading split into its parts using the
+	// document's vocabulary (see ParseSceneHeading)
+	HeadingParts *SceneHeading `json:"heading_parts,omitempty" yaml:"heading_parts,omitempty"`
+	// Body holds the paragraphs after the scene heading, including any
+	// sections, boneyard or blank paragraphs
+	Body []*Para `json:"-" yaml:"-"`
+	// Number is the scene number if scenes are numbered
+	Number string `json:"number,omitempty" yaml:"number,omitempty"`
+	// FirstPage and LastPage are the pages the scene is laid out on,
+	// only ScenesOnPages fills them in, they are empty from Scenes
+	FirstPage string `json:"first_page,omitempty" yaml:"first_page,omitempty"`
+	LastPage  string `json:"last_page,omitempty" yaml:"last_page,omitempty"`
+}
+
+// DialogueBlock is a character cue and the parentheticals and dialogue
+// spoken after it
+type DialogueBlock struct {
+	// Cue is the character paragraph
+	Cue *Para `json:"-" yaml:"-"`
+	// Character is the name in the cue in upper case without extensions
+	Character string `json:"character" yaml:"character"`
+	// Extension holds the cue's extensions, e.g. "(V.O.)"
+	Extension string `json:"extension,omitempty" yaml:"extension,omitempty"`
+	// Parentheticals and Lines are the parenthetical and dialogue (or
+	// singing) paragraphs of the block, Paras holds both in order
+	Parentheticals []*Para `json:"-" yaml:"-"`
+	Lines          []*Para `json:"-" yaml:"-"`
+	Paras          []*Para `json:"-" yaml:"-"`
+}
+
+// isPrinted reports if a paragraph is printed text that isn't blank
+func (para *Para) isPrinted() bool {
+	return !para.IsStructural() && strings.TrimSpace(para.PlainText()) != ""
+}
+
+// Scenes returns the scenes of the screenplay in order along with their
+// scene numbers (see shownSceneNumbers). If there is printed text before
+// the first scene heading it is returned as a scene without a heading.
+// Scene headings without text don't start a scene. The pages aren't
+// filled in, see ScenesOnPages.
+func (document *OpenScreenplay) Scenes() []*Scene {
+	scenes := []*Scene{}
+	if document == nil || document.Paragraphs == nil {
+		return scenes
+	}
+	numbers := document.shownSceneNumbers()
+	var scene *Scene
+	for _, para := range document.Paragraphs.Para {
+		if para.isSceneHeading() && para.isPrinted() {
+			scene = &Scene{Heading: para, HeadingParts: document.ParseSceneHeading(para.PlainText()), Number: numbers[para]}
+			scenes = append(scenes, scene)
+			continue
+		}
+		if scene == nil {
+			if !para.isPrinted() {
+				continue
+			}
+			scene = new(Scene)
+			scenes = append(scenes, scene)
+		}
+		scene.Body = append(scene.Body, para)
+	}
+	return scenes
+}
+
+// ScenesOnPages returns the scenes as Scenes does with the pages they
+// are laid out on, pages is the screenplay's Layout
+func (document *OpenScreenplay) ScenesOnPages(pages []*Page) []*Scene {
+	scenes := document.Scenes()
+	paraPages := map[*Para][]string{}
+	for _, page := range pages {
+		for _, line := range page.Lines {
+			if line.Para != nil {
+				if seen := paraPages[line.Para]; len(seen) == 0 || seen[len(seen)-1] != page.Number {
+					paraPages[line.Para] = append(seen, page.Number)
+				}
+			}
+		}
+	}
+	for _, scene := range scenes {
+		for _, para := range append([]*Para{scene.Heading}, scene.Body...) {
+			if numbers := paraPages[para]; len(numbers) > 0 {
+				if scene.FirstPage == "" {
+					scene.FirstPage = numbers[0]
+				}
+				scene.LastPage = numbers[len(numbers)-1]
+			}
+		}
+	}
+	return scenes
+}
+
+// HeadingText returns the text of the scene heading, it is empty for the
+// scene before the first scene heading
+func (scene *Scene) HeadingText() string {
+	if scene.Heading == nil {
+		return ""
+	}
+	return strings.TrimSpace(scene.Heading.PlainText())
+}
+
+// Location returns the location and sub-location named in the scene
+// heading in upper case, "INT. HOUSE - KITCHEN - DAY" is at
+// "HOUSE - KITCHEN"
+func (scene *Scene) Location() string {
+	if scene.HeadingParts == nil {
+		return ""
+	}
+	return strings.ToUpper(scene.HeadingParts.Place())
+}
+
+// split divides the printed paragraphs of the scene into dialogue
+// blocks and the paragraphs that aren't part of one, dialogue is spoken
+// by the character of the cue before it
+func (scene *Scene) split() ([]*DialogueBlock, []*Para) {
+	blocks, action := []*DialogueBlock{}, []*Para{}
+	var block *DialogueBlock
+	for _, para := range scene.Body {
+		if !para.isPrinted() {
+			continue
+		}
+		switch paraStyleName(para) {
+		case CharacterType:
+			block = newDialogueBlock(para)
+			blocks = append(blocks, block)
+			continue
+		case ParentheticalType:
+			if block != nil {
+				block.Parentheticals = append(block.Parentheticals, para)
+				block.Paras = append(block.Paras, para)
+				continue
+			}
+		case DialogueType, SingingType:
+			if block != nil {
+				block.Lines = append(block.Lines, para)
+				block.Paras = append(block.Paras, para)
+				continue
+			}
+		default:
+			block = nil
+		}
+		action = append(action, para)
+	}
+	return blocks, action
+}
+
+// DialogueBlocks returns the dialogue blocks of the scene, dialogue is
+// spoken by the character of the cue before it
+func (scene *Scene) DialogueBlocks() []*DialogueBlock {
+	blocks, _ := scene.split()
+	return blocks
+}
+
+// Action returns the printed paragraphs of the scene that aren't part of
+// a dialogue block, e.g. action, shots and transitions
+func (scene *Scene) Action() []*Para {
+	_, action := scene.split()
+	return action
+}
+
+// Characters returns the characters who speak in the scene in the order
+// they first speak
+func (scene *Scene) Characters() []string {
+	characters, seen := []string{}, map[string]bool{}
+	for _, block := range scene.DialogueBlocks() {
+		if !seen[block.Character] {
+			seen[block.Character] = true
+			characters = append(characters, block.Character)
+		}
+	}
+	return characters
+}
+
+// cueName returns the character named by a character cue without its
+// extensions, e.g. "Programmer (V.O.)" is "PROGRAMMER"
+func cueName(s string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(cueExtensionRE.ReplaceAllString(s, " "))), " ")
+}
+
+// newDialogueBlock starts a dialogue block with a character cue, the
+// name is split from its extensions, e.g. "Ann (V.O.)" is ANN with the
+// extension "(V.O.)"
+func newDialogueBlock(cue *Para) *DialogueBlock {
+	text := cue.PlainText()
+	return &DialogueBlock{
+		Cue:       cue,
+		Character: cueName(text),
+		Extension: strings.Join(cueExtensionRE.FindAllString(text, -1), " "),
+	}
+}
+
+// Text returns what is said in the block, parentheticals are in
+// parentheses, e.g. "(smiling) Good morning."
+func (block *DialogueBlock) Text() string {
+	src := []string{}
+	for _, para := range block.Paras {
+		text := strings.TrimSpace(para.PlainText())
+		if paraStyleName(para) == ParentheticalType && !strings.HasPrefix(text, "(") {
+			text = "(" + text + ")"
+		}
+		src = append(src, text)
+	}
+	return strings.Join(src, " ")
+}
+
+// DialogueBlocks returns the dialogue blocks of the screenplay in order
+func (document *OpenScreenplay) DialogueBlocks() []*DialogueBlock {
+	blocks := []*DialogueBlock{}
+	for _, scene := range document.Scenes() {
+		blocks = append(blocks, scene.DialogueBlocks()...)
+	}
+	return blocks
+}
+
+// Characters returns the characters who speak in the screenplay in the
+// order they first speak
+func (document *OpenScreenplay) Characters() []string {
+	characters, seen := []string{}, map[string]bool{}
+	for _, block := range document.DialogueBlocks() {
+		if !seen[block.Character] {
+			seen[block.Character] = true
+			characters = append(characters, block.Character)
+		}
+	}
+	return characters
+}
+
+// Locations returns the locations of the scenes (see Scene.Location) in
+// the order they first appear
+func (document *OpenScreenplay) Locations() []string {
+	locations, seen := []string{}, map[string]bool{}
+	for _, scene := range document.Scenes() {
+		if location := scene.Location(); location != "" && !seen[location] {
+			seen[location] = true
+			locations = append(locations, location)
+		}
+	}
+	return locations
+}
@@ -0,0 +1,98 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScenes(t *testing.T) {
+	document := scriptDocument(
+		"Transition: FADE IN:",
+		"Scene Heading: INT. HOUSE - KITCHEN - DAY",
+		"Action: Ann makes coffee.",
+		"Character: Ann (V.O.) (CONT'D)",
+		"Parenthetical: smiling",
+		"Dialogue: Good morning.",
+		"Character: Bob",
+		"Dialogue: Coffee?",
+		"Section: Act two",
+		"Scene Heading: EXT. GARDEN - NIGHT",
+		"Dialogue: Who said that?",
+		"Character: BOB",
+		"Dialogue: Me.",
+		"Scene Heading: ",
+		"Scene Heading: I/E. ANN'S CAR - DAY",
+	)
+	document.NumberScenes()
+	scenes := document.ScenesOnPages(document.Layout())
+	if len(scenes) != 4 {
+		t.Fatalf("expected four scenes, got %d", len(scenes))
+	}
+	for i, expected := range []struct {
+		heading, number, location, characters string
+		body, action                          int
+	}{
+		{"", "", "", "", 1, 1},
+		{"INT. HOUSE - KITCHEN - DAY", "1", "HOUSE - KITCHEN", "ANN BOB", 7, 1},
+		{"EXT. GARDEN - NIGHT", "2", "GARDEN", "BOB", 4, 1},
+		{"I/E. ANN'S CAR - DAY", "3", "ANN'S CAR", "", 0, 0},
+	} {
+		scene := scenes[i]
+		if scene.HeadingText() != expected.heading || scene.Number != expected.number || scene.Location() != expected.location ||
+			strings.Join(scene.Characters(), " ") != expected.characters || len(scene.Body) != expected.body || len(scene.Action()) != expected.action {
+			t.Errorf("scene %d, expected %+v, got %q %q %q %q %d %d", i, expected, scene.HeadingText(), scene.Number, scene.Location(),
+				scene.Characters(), len(scene.Body), len(scene.Action()))
+		}
+		if scene.FirstPage != "1" || scene.LastPage != "1" {
+			t.Errorf("scene %d, expected page 1, got %s-%s", i, scene.FirstPage, scene.LastPage)
+		}
+	}
+	// Scenes doesn't lay out the screenplay
+	if scene := document.Scenes()[0]; scene.FirstPage != "" || scene.LastPage != "" {
+		t.Errorf("expected no pages from Scenes, got %s-%s", scene.FirstPage, scene.LastPage)
+	}
+
+	blocks := document.DialogueBlocks()
+	if len(blocks) != 3 {
+		t.Fatalf("expected three dialogue blocks, got %d", len(blocks))
+	}
+	if block := blocks[0]; block.Character != "ANN" || block.Extension != "(V.O.) (CONT'D)" || len(block.Parentheticals) != 1 ||
+		len(block.Lines) != 1 || block.Text() != "(smiling) Good morning." {
+		t.Errorf("unexpected dialogue block %+v", block)
+	}
+	if s := strings.Join(document.Characters(), ", "); s != "ANN, BOB" {
+		t.Errorf("expected ANN and BOB to speak, got %q", s)
+	}
+	if s := strings.Join(document.Locations(), ", "); s != "HOUSE - KITCHEN, GARDEN, ANN'S CAR" {
+		t.Errorf("unexpected locations %q", s)
+	}
+}
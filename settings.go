@@ -0,0 +1,545 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Length is a distance in tenths of a millimetre, the unit OSF uses for
+// page geometry, margins and indents (e.g. page_width="2159" is 215.9mm).
+type Length int
+
+const (
+	// Millimetre is one millimetre expressed as a Length
+	Millimetre Length = 10
+	// Inch is one inch expressed as a Length
+	Inch Length = 254
+
+	// LetterWidth and LetterHeight are the dimensions of a US Letter page
+	LetterWidth  Length = 2159
+	LetterHeight Length = 2794
+	// A4Width and A4Height are the dimensions of an A4 page
+	A4Width  Length = 2100
+	A4Height Length = 2970
+
+	// DefaultLinesPerInch is used when normal_linesperinch is not set
+	DefaultLinesPerInch = 6.0
+)
+
+// Default page geometry, used when a document's settings are missing,
+// matches the values Fade In writes for a US Letter screenplay.
+var (
+	DefaultMarginTop    Length = 317
+	DefaultMarginBottom Length = 220
+	DefaultMarginLeft   Length = 317
+	DefaultMarginRight  Length = 317
+)
+
+// LengthFromInches converts inches to a Length
+func LengthFromInches(inches float64) Length {
+	return Length(math.Round(inches * float64(Inch)))
+}
+
+// LengthFromMillimetres converts millimetres to a Length
+func LengthFromMillimetres(mm float64) Length {
+	return Length(math.Round(mm * float64(Millimetre)))
+}
+
+// LengthFromPoints converts points (1/72 inch) to a Length
+func LengthFromPoints(points float64) Length {
+	return LengthFromInches(points / 72)
+}
+
+// ParseLength parses an OSF length attribute value
+func ParseLength(s string) (Length, error) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, err
+	}
+	return Length(math.Round(f)), nil
+}
+
+// Millimetres returns the length in millimetres
+func (l Length) Millimetres() float64 {
+	return float64(l) / float64(Millimetre)
+}
+
+// Inches returns the length in inches
+func (l Length) Inches() float64 {
+	return float64(l) / float64(Inch)
+}
+
+// Points returns the length in points (1/72 inch)
+func (l Length) Points() float64 {
+	return l.Inches() * 72
+}
+
+// String returns the length as an OSF attribute value
+func (l Length) String() string {
+	return strconv.Itoa(int(l))
+}
+
+// NumberingMode describes how inserted pages or scenes are numbered
+// once numbering has been locked.
+type NumberingMode string
+
+const (
+	// NumberingModeSuffix adds letters after the number, e.g. 12, 12A, 12B
+	NumberingModeSuffix NumberingMode = "1AB"
+	// NumberingModePrefix adds letters before the number, e.g. 12, A12, B12
+	NumberingModePrefix NumberingMode = "A1"
+)
+
+// Flag names a boolean attribute of the settings element
+type Flag string
+
+const (
+	BreakOnSentencesFlag   Flag = "break_on_sentences"
+	DialogueContinuesFlag  Flag = "dialogue_continues"
+	DialoguePageBreaksFlag Flag = "dialogue_pagebreaks"
+	ScenesContinueFlag     Flag = "scenes_continue"
+	NumberContinuedFlag    Flag = "number_continued"
+	AutoOmitScenesFlag     Flag = "auto_omit_scenes"
+	HeaderFirstPageFlag    Flag = "header_first_page"
+	FooterFirstPageFlag    Flag = "footer_first_page"
+	PageNumberingFlag      Flag = "page_numbering"
+	PageNumberFirstFlag    Flag = "pagenumber_first"
+	PagesLockedFlag        Flag = "pages_locked"
+	SceneNumberingFlag     Flag = "scene_numbering"
+	SceneNumberSkipIOFlag  Flag = "scenenumber_skip_io"
+	ScenesLockedFlag       Flag = "scenes_locked"
+	DialogueNumberingFlag  Flag = "dialogue_numbering"
+	DialogueLockedFlag     Flag = "dialogue_locked"
+	ShowRevisionsFlag      Flag = "show_revisions"
+	ShowAllRevisionsFlag   Flag = "show_all_revisions"
+)
+
+// ParseBool returns true for the values OSF uses for true ("true", "1", "yes")
+func ParseBool(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "true", "1", "yes":
+		return true
+	}
+	return false
+}
+
+// FormatBool returns the OSF attribute value for a boolean
+func FormatBool(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
+
+// flag returns a pointer to the string field holding a boolean attribute
+func (settings *Settings) flag(flag Flag) *string {
+	switch flag {
+	case BreakOnSentencesFlag:
+		return &settings.BreakOnSentences
+	case DialogueContinuesFlag:
+		return &settings.DialogueContinues
+	case DialoguePageBreaksFlag:
+		return &settings.DialoguePageBreaks
+	case ScenesContinueFlag:
+		return &settings.ScenesContinue
+	case NumberContinuedFlag:
+		return &settings.NumberContinued
+	case AutoOmitScenesFlag:
+		return &settings.AutoOmitScenes
+	case HeaderFirstPageFlag:
+		return &settings.HeaderFirstPage
+	case FooterFirstPageFlag:
+		return &settings.FooterFirstPage
+	case PageNumberingFlag:
+		return &settings.PageNumbering
+	case PageNumberFirstFlag:
+		return &settings.PageNumberFirst
+	case PagesLockedFlag:
+		return &settings.PagesLocked
+	case SceneNumberingFlag:
+		return &settings.SceneNumbering
+	case SceneNumberSkipIOFlag:
+		return &settings.SceneNumberSkipIO
+	case ScenesLockedFlag:
+		return &settings.ScenesLocked
+	case DialogueNumberingFlag:
+		return &settings.DialogueNumbering
+	case DialogueLockedFlag:
+		return &settings.DialogueLocked
+	case ShowRevisionsFlag:
+		return &settings.ShowRevisions
+	case ShowAllRevisionsFlag:
+		return &settings.ShowAllRevisions
+	}
+	return nil
+}
+
+// Flag returns the value of a boolean setting, false if it is not set.
+func (settings *Settings) Flag(flag Flag) bool {
+	if settings == nil {
+		return false
+	}
+	if p := settings.flag(flag); p != nil {
+		return ParseBool(*p)
+	}
+	return false
+}
+
+// SetFlag sets a boolean setting
+func (settings *Settings) SetFlag(flag Flag, value bool) error {
+	p := settings.flag(flag)
+	if p == nil {
+		return fmt.Errorf("unknown settings flag %q", flag)
+	}
+	*p = FormatBool(value)
+	return nil
+}
+
+// lengthOrDefault parses s returning defaultValue if s is empty or invalid
+func lengthOrDefault(s string, defaultValue Length) Length {
+	if l, err := ParseLength(s); err == nil {
+		return l
+	}
+	return defaultValue
+}
+
+// intOrDefault parses s returning defaultValue if s is empty or invalid
+func intOrDefault(s string, defaultValue int) int {
+	if i, err := strconv.Atoi(strings.TrimSpace(s)); err == nil {
+		return i
+	}
+	return defaultValue
+}
+
+// floatOrDefault parses s returning defaultValue if s is empty or invalid
+func floatOrDefault(s string, defaultValue float64) float64 {
+	if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil && f > 0 {
+		return f
+	}
+	return defaultValue
+}
+
+// formatFloat formats a float the way Fade In writes them, e.g. "6.0"
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 1, 64)
+}
+
+// PageSize returns the page width and height, US Letter if not set.
+func (settings *Settings) PageSize() (Length, Length) {
+	if settings == nil {
+		return LetterWidth, LetterHeight
+	}
+	return lengthOrDefault(settings.PageWidth, LetterWidth), lengthOrDefault(settings.PageHeight, LetterHeight)
+}
+
+// SetPageSize sets the page width and height
+func (settings *Settings) SetPageSize(width, height Length) {
+	settings.PageWidth, settings.PageHeight = width.String(), height.String()
+}
+
+// Margins returns the top, bottom, left and right page margins
+func (settings *Settings) Margins() (Length, Length, Length, Length) {
+	if settings == nil {
+		return DefaultMarginTop, DefaultMarginBottom, DefaultMarginLeft, DefaultMarginRight
+	}
+	return lengthOrDefault(settings.MarginTop, DefaultMarginTop),
+		lengthOrDefault(settings.MarginBottom, DefaultMarginBottom),
+		lengthOrDefault(settings.MarginLeft, DefaultMarginLeft),
+		lengthOrDefault(settings.MarginRight, DefaultMarginRight)
+}
+
+// SetMargins sets the top, bottom, left and right page margins
+func (settings *Settings) SetMargins(top, bottom, left, right Length) {
+	settings.MarginTop = top.String()
+	settings.MarginBottom = bottom.String()
+	settings.MarginLeft = left.String()
+	settings.MarginRight = right.String()
+}
+
+// TextWidth returns the width of the page between the left and right margins
+func (settings *Settings) TextWidth() Length {
+	width, _ := settings.PageSize()
+	_, _, left, right := settings.Margins()
+	return width - left - right
+}
+
+// TextHeight returns the height of the page between the top and bottom margins
+func (settings *Settings) TextHeight() Length {
+	_, height := settings.PageSize()
+	top, bottom, _, _ := settings.Margins()
+	return height - top - bottom
+}
+
+// LinesPerInch returns normal_linesperinch, six if not set
+func (settings *Settings) LinesPerInch() float64 {
+	if settings == nil {
+		return DefaultLinesPerInch
+	}
+	return floatOrDefault(settings.NormalLinesPerInch, DefaultLinesPerInch)
+}
+
+// SetLinesPerInch sets normal_linesperinch
+func (settings *Settings) SetLinesPerInch(linesPerInch float64) {
+	settings.NormalLinesPerInch = formatFloat(linesPerInch)
+}
+
+// LinesPerPage returns the number of lines that fit between the top and
+// bottom margins
+func (settings *Settings) LinesPerPage() int {
+	return int(math.Floor(settings.TextHeight().Inches()*settings.LinesPerInch() + 0.001))
+}
+
+// MoreLabel returns more_text, the label at the bottom of a page when
+// dialogue continues on the next, "(MORE)" if not set
+func (settings *Settings) MoreLabel() string {
+	if settings == nil || strings.TrimSpace(settings.MoreText) == "" {
+		return "(MORE)"
+	}
+	return settings.MoreText
+}
+
+// ContLabel returns cont_text, the label added to a character's name when
+// their dialogue continues, "(CONT'D)" if not set
+func (settings *Settings) ContLabel() string {
+	if settings == nil || strings.TrimSpace(settings.ContText) == "" {
+		return "(CONT'D)"
+	}
+	return settings.ContText
+}
+
+// TimeSeparator returns scene_time_separator, what separates the parts of
+// a scene heading (e.g. the location and time of day), " - " if not set
+func (settings *Settings) TimeSeparator() string {
+	if settings == nil || strings.TrimSpace(settings.SceneTimeSeparator) == "" {
+		return " - "
+	}
+	return settings.SceneTimeSeparator
+}
+
+// Spacing returns the element_spacing multiplier, one if not set
+func (settings *Settings) Spacing() float64 {
+	if settings == nil {
+		return 1.0
+	}
+	return floatOrDefault(settings.ElementSpacing, 1.0)
+}
+
+// SetSpacing sets the element_spacing multiplier
+func (settings *Settings) SetSpacing(spacing float64) {
+	settings.ElementSpacing = formatFloat(spacing)
+}
+
+// PageMode returns the numbering mode used for pages inserted after locking
+func (settings *Settings) PageMode() NumberingMode {
+	if settings == nil || settings.PageNumberMode == "" {
+		return NumberingModeSuffix
+	}
+	return NumberingMode(settings.PageNumberMode)
+}
+
+// SetPageMode sets the numbering mode used for pages inserted after locking
+func (settings *Settings) SetPageMode(mode NumberingMode) {
+	settings.PageNumberMode = string(mode)
+}
+
+// SceneMode returns the numbering mode used for scenes inserted after locking
+func (settings *Settings) SceneMode() NumberingMode {
+	if settings == nil || settings.SceneNumberMode == "" {
+		return NumberingModeSuffix
+	}
+	return NumberingMode(settings.SceneNumberMode)
+}
+
+// SetSceneMode sets the numbering mode used for scenes inserted after locking
+func (settings *Settings) SetSceneMode(mode NumberingMode) {
+	settings.SceneNumberMode = string(mode)
+}
+
+// FirstPageNumber returns pagenumber_start, one if not set
+func (settings *Settings) FirstPageNumber() int {
+	if settings == nil {
+		return 1
+	}
+	return intOrDefault(settings.PageNumberStart, 1)
+}
+
+// SetFirstPageNumber sets pagenumber_start
+func (settings *Settings) SetFirstPageNumber(i int) {
+	settings.PageNumberStart = strconv.Itoa(i)
+}
+
+// FirstSceneNumber returns scenenumber_start, one if not set
+func (settings *Settings) FirstSceneNumber() int {
+	if settings == nil {
+		return 1
+	}
+	return intOrDefault(settings.SceneNumberStart, 1)
+}
+
+// SetFirstSceneNumber sets scenenumber_start
+func (settings *Settings) SetFirstSceneNumber(i int) {
+	settings.SceneNumberStart = strconv.Itoa(i)
+}
+
+// FirstDialogueNumber returns dialoguenumber_start, one if not set
+func (settings *Settings) FirstDialogueNumber() int {
+	if settings == nil {
+		return 1
+	}
+	return intOrDefault(settings.DialogueNumberStart, 1)
+}
+
+// SetFirstDialogueNumber sets dialoguenumber_start
+func (settings *Settings) SetFirstDialogueNumber(i int) {
+	settings.DialogueNumberStart = strconv.Itoa(i)
+}
+
+// RevisionNumber returns the current revision, zero if not set
+func (settings *Settings) RevisionNumber() int {
+	if settings == nil {
+		return 0
+	}
+	return intOrDefault(settings.Revision, 0)
+}
+
+// SetRevisionNumber sets the current revision
+func (settings *Settings) SetRevisionNumber(i int) {
+	settings.Revision = strconv.Itoa(i)
+}
+
+// alignmentFromPosition maps header_alignment/footer_alignment values
+// (1 left, 2 center, 3 right) to an alignment.
+func alignmentFromPosition(s string) string {
+	switch strings.TrimSpace(s) {
+	case "1":
+		return LeftAlignment
+	case "2":
+		return CenterAlignment
+	}
+	return RightAlignment
+}
+
+// positionFromAlignment is the inverse of alignmentFromPosition
+func positionFromAlignment(align string) string {
+	switch strings.ToLower(align) {
+	case "left":
+		return "1"
+	case "center":
+		return "2"
+	}
+	return "3"
+}
+
+// HeaderAlign returns the alignment of the page header (Left, Center or Right)
+func (settings *Settings) HeaderAlign() string {
+	if settings == nil {
+		return RightAlignment
+	}
+	return alignmentFromPosition(settings.HeaderAlignment)
+}
+
+// SetHeaderAlign sets the alignment of the page header
+func (settings *Settings) SetHeaderAlign(align string) {
+	settings.HeaderAlignment = positionFromAlignment(align)
+}
+
+// FooterAlign returns the alignment of the page footer (Left, Center or Right)
+func (settings *Settings) FooterAlign() string {
+	if settings == nil {
+		return RightAlignment
+	}
+	return alignmentFromPosition(settings.FooterAlignment)
+}
+
+// SetFooterAlign sets the alignment of the page footer
+func (settings *Settings) SetFooterAlign(align string) {
+	settings.FooterAlignment = positionFromAlignment(align)
+}
+
+// SceneNumberSides reports if scene numbers are shown in the left and/or
+// right margins. scenenumber_position is a bit field, 1 left, 2 right.
+func (settings *Settings) SceneNumberSides() (bool, bool) {
+	position := 3
+	if settings != nil {
+		position = intOrDefault(settings.SceneNumberPosition, 3)
+	}
+	return position&1 == 1, position&2 == 2
+}
+
+// SetSceneNumberSides sets which margins scene numbers are shown in
+func (settings *Settings) SetSceneNumberSides(left, right bool) {
+	position := 0
+	if left {
+		position |= 1
+	}
+	if right {
+		position |= 2
+	}
+	settings.SceneNumberPosition = strconv.Itoa(position)
+}
+
+// FormatPageNumber applies the page_header (or 1.2's pagenumber_format)
+// template to a page number, e.g. "#." and "12" gives "12."
+func (settings *Settings) FormatPageNumber(pageNo string) string {
+	format := "#."
+	if settings != nil {
+		if settings.PageHeader != "" {
+			format = settings.PageHeader
+		} else if settings.PageNumberFormat != "" {
+			format = settings.PageNumberFormat
+		}
+	}
+	return strings.Replace(format, "#", pageNo, -1)
+}
+
+// showPageHeader reports if a page has the page header, every page but
+// the first unless header_first_page (or 1.2's pagenumber_first) is set
+func (settings *Settings) showPageHeader(page *Page) bool {
+	if page.Number != strconv.Itoa(settings.FirstPageNumber()) {
+		return true
+	}
+	if settings != nil && strings.TrimSpace(settings.HeaderFirstPage) != "" {
+		return settings.Flag(HeaderFirstPageFlag)
+	}
+	return settings.Flag(PageNumberFirstFlag)
+}
+
+// FormatSceneNumber applies the scenenumber_format template to a scene number
+func (settings *Settings) FormatSceneNumber(sceneNo string) string {
+	format := "#"
+	if settings != nil && settings.SceneNumberFormat != "" {
+		format = settings.SceneNumberFormat
+	}
+	return strings.Replace(format, "#", sceneNo, -1)
+}
@@ -0,0 +1,139 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"math"
+	"path"
+	"testing"
+)
+
+func TestSettings(t *testing.T) {
+	doc, err := ParseFile(path.Join("testdata", "OSF-2.0.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings := doc.Settings
+	if settings.SceneTimeSeparator != " - " || settings.PageHeader != "#." || settings.DialogueNumberFormat != "()" {
+		t.Errorf("expected OSF 2.0 settings to be populated, got %+v", settings)
+	}
+	width, height := settings.PageSize()
+	if width != LetterWidth || height != LetterHeight {
+		t.Errorf("expected US Letter, got %d x %d", width, height)
+	}
+	if math.Abs(width.Inches()-8.5) > 0.01 || math.Abs(height.Millimetres()-279.4) > 0.01 {
+		t.Errorf("expected 8.5in x 279.4mm, got %fin x %fmm", width.Inches(), height.Millimetres())
+	}
+	top, bottom, left, right := settings.Margins()
+	if top != 317 || bottom != 220 || left != 317 || right != 317 {
+		t.Errorf("unexpected margins %d, %d, %d, %d", top, bottom, left, right)
+	}
+	if settings.TextWidth() != 2159-634 {
+		t.Errorf("unexpected text width %d", settings.TextWidth())
+	}
+	if settings.LinesPerInch() != 6.0 || settings.Spacing() != 1.0 {
+		t.Errorf("unexpected lines per inch %f or spacing %f", settings.LinesPerInch(), settings.Spacing())
+	}
+	for flag, expected := range map[Flag]bool{
+		BreakOnSentencesFlag:  true,
+		ScenesContinueFlag:    true,
+		AutoOmitScenesFlag:    false,
+		PagesLockedFlag:       true,
+		ScenesLockedFlag:      true,
+		DialogueNumberingFlag: false,
+		ShowAllRevisionsFlag:  true,
+	} {
+		if settings.Flag(flag) != expected {
+			t.Errorf("expected %s to be %t", flag, expected)
+		}
+	}
+	if settings.PageMode() != NumberingModeSuffix || settings.SceneMode() != NumberingModeSuffix {
+		t.Errorf("expected 1AB numbering modes, got %q and %q", settings.PageMode(), settings.SceneMode())
+	}
+	if settings.RevisionNumber() != 2 || settings.FirstPageNumber() != 1 {
+		t.Errorf("unexpected revision %d or first page %d", settings.RevisionNumber(), settings.FirstPageNumber())
+	}
+	if settings.HeaderAlign() != RightAlignment {
+		t.Errorf("expected header to be right aligned, got %s", settings.HeaderAlign())
+	}
+	if left, right := settings.SceneNumberSides(); !left || !right {
+		t.Errorf("expected scene numbers in both margins")
+	}
+	if s := settings.FormatPageNumber("12A"); s != "12A." {
+		t.Errorf("expected 12A., got %q", s)
+	}
+
+	// Setters write valid attribute values
+	settings.SetPageSize(A4Width, A4Height)
+	settings.SetMargins(LengthFromInches(1), LengthFromInches(1), LengthFromInches(1.5), LengthFromInches(1))
+	settings.SetLinesPerInch(6)
+	settings.SetPageMode(NumberingModePrefix)
+	settings.SetHeaderAlign(CenterAlignment)
+	if err := settings.SetFlag(PagesLockedFlag, false); err != nil {
+		t.Error(err)
+	}
+	if err := settings.SetFlag(Flag("no_such_flag"), false); err == nil {
+		t.Errorf("expected an error setting an unknown flag")
+	}
+	if settings.PageWidth != "2100" || settings.PageHeight != "2970" || settings.MarginLeft != "381" {
+		t.Errorf("unexpected page geometry %+v", settings)
+	}
+	if settings.NormalLinesPerInch != "6.0" || settings.PagesLocked != "false" || settings.PageNumberMode != "A1" || settings.HeaderAlignment != "2" {
+		t.Errorf("unexpected settings %+v", settings)
+	}
+
+	// The first page has a header if header_first_page is set, 1.2's
+	// pagenumber_first otherwise
+	first, second := &Page{Number: "1"}, &Page{Number: "2"}
+	for _, test := range []struct {
+		headerFirstPage, pageNumberFirst string
+		expected                         bool
+	}{
+		{"", "", false},
+		{"true", "", true},
+		{"false", "true", false},
+		{"", "true", true},
+	} {
+		settings := &Settings{HeaderFirstPage: test.headerFirstPage, PageNumberFirst: test.pageNumberFirst}
+		if settings.showPageHeader(first) != test.expected || !settings.showPageHeader(second) {
+			t.Errorf("header_first_page %q and pagenumber_first %q, expected a header on the first page %t",
+				test.headerFirstPage, test.pageNumberFirst, test.expected)
+		}
+	}
+
+	// A document without settings gets sensible defaults
+	var empty *Settings
+	if width, height := empty.PageSize(); width != LetterWidth || height != LetterHeight {
+		t.Errorf("expected US Letter defaults, got %d x %d", width, height)
+	}
+	if empty.LinesPerInch() != DefaultLinesPerInch || empty.Flag(PagesLockedFlag) {
+		t.Errorf("unexpected defaults for nil settings")
+	}
+}
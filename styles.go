@@ -0,0 +1,182 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EffectiveStyle is the style a paragraph is shown in once the styles
+// it is based on and its own overrides are resolved
+type EffectiveStyle struct {
+	// Name is the named style the paragraph uses, e.g. "Dialogue"
+	Name string `json:"name" yaml:"name"`
+	// Chain lists the named styles resolved, the paragraph's style
+	// first followed by the styles it is based on
+	Chain []string `json:"chain,omitempty" yaml:"chain,omitempty"`
+	Font  string   `json:"font,omitempty" yaml:"font,omitempty"`
+	// Size is the font size in points, 12 if not set
+	Size float64 `json:"size" yaml:"size"`
+	// SpaceBefore is the blank lines before the paragraph (before
+	// element_spacing is applied)
+	SpaceBefore float64 `json:"spacebefore" yaml:"spacebefore"`
+	// LineSpacing is one for single spaced lines
+	LineSpacing float64 `json:"linespacing" yaml:"linespacing"`
+	// LeftIndent and RightIndent are from the page margins
+	LeftIndent  Length `json:"leftindent" yaml:"leftindent"`
+	RightIndent Length `json:"rightindent" yaml:"rightindent"`
+	// Align is LeftAlignment, CenterAlignment or RightAlignment
+	Align           string `json:"align" yaml:"align"`
+	KeepWithNext    bool   `json:"keepwithnext,omitempty" yaml:"keepwithnext,omitempty"`
+	PageBreakBefore bool   `json:"pagebreakbefore,omitempty" yaml:"pagebreakbefore,omitempty"`
+	AllCaps         bool   `json:"allcaps,omitempty" yaml:"allcaps,omitempty"`
+	Bold            bool   `json:"bold,omitempty" yaml:"bold,omitempty"`
+	Italic          bool   `json:"italic,omitempty" yaml:"italic,omitempty"`
+	Underline       bool   `json:"underline,omitempty" yaml:"underline,omitempty"`
+}
+
+// findStyle returns the document's style with name, nil if there isn't one
+func (document *OpenScreenplay) findStyle(name string) *Style {
+	if document != nil && document.Styles != nil {
+		for _, style := range document.Styles.Style {
+			if style.Name == name {
+				return style
+			}
+		}
+	}
+	return nil
+}
+
+// builtinStyle returns the built in (or structural) style with name, nil
+// if there isn't one
+func builtinStyle(name string) *Style {
+	for _, style := range append(builtinStyles, structuralStyles...) {
+		if style.Name == name {
+			return style
+		}
+	}
+	return nil
+}
+
+// normalizeAlign maps an align attribute (e.g. "center") to one of the
+// alignment constants
+func normalizeAlign(align string) string {
+	switch strings.ToLower(strings.TrimSpace(align)) {
+	case "center", "centre":
+		return CenterAlignment
+	case "right":
+		return RightAlignment
+	}
+	return LeftAlignment
+}
+
+// StyleOf resolves the style para is shown in. Each attribute is taken
+// from the first of the paragraph's own style, the document's style and
+// then the styles it is based on that sets it. A built in style is used
+// for a name the document doesn't define. A paragraph without a style is
+// Normal Text.
+//
+// An error is returned if a style isn't defined by the document or built
+// in, or styles are based on each other in a loop. The style resolved
+// up to that point is still returned so a document can be shown.
+func (document *OpenScreenplay) StyleOf(para *Para) (*EffectiveStyle, error) {
+	chain := []*Style{}
+	name := GeneralType
+	if para != nil && para.Style != nil {
+		chain = append(chain, para.Style)
+		if s := strings.TrimSpace(para.Style.BaseStyleName); s != "" {
+			name = s
+		}
+	}
+	effective := &EffectiveStyle{Name: name}
+	var err error
+	seen := map[string]bool{}
+	for base := name; base != ""; {
+		if seen[base] {
+			err = fmt.Errorf("style %q is based on itself, %s", base, strings.Join(append(effective.Chain, base), " -> "))
+			break
+		}
+		seen[base] = true
+		defined, builtin := document.findStyle(base), builtinStyle(base)
+		if defined == nil && builtin == nil {
+			if len(effective.Chain) == 0 {
+				err = fmt.Errorf("style %q is not defined", base)
+			} else {
+				err = fmt.Errorf("style %q is based on %q which is not defined", effective.Chain[len(effective.Chain)-1], base)
+			}
+			break
+		}
+		effective.Chain = append(effective.Chain, base)
+		style := defined
+		if style == nil {
+			style = builtin
+		}
+		chain = append(chain, style)
+		base = strings.TrimSpace(style.BaseStyleName)
+	}
+	first := func(attr func(*Style) string) string {
+		for _, style := range chain {
+			if s := strings.TrimSpace(attr(style)); s != "" {
+				return s
+			}
+		}
+		return ""
+	}
+	effective.Font = first(func(s *Style) string { return s.Font })
+	effective.Size = floatOrDefault(first(func(s *Style) string { return s.Size }), 12)
+	effective.SpaceBefore = floatOrDefault(first(func(s *Style) string { return s.SpaceBefore }), 0)
+	effective.LineSpacing = floatOrDefault(first(func(s *Style) string { return s.LineSpacing }), 1)
+	effective.LeftIndent = lengthOrDefault(first(func(s *Style) string { return s.LeftIdent }), 0)
+	effective.RightIndent = lengthOrDefault(first(func(s *Style) string { return s.RightIdent }), 0)
+	effective.Align = normalizeAlign(first(func(s *Style) string { return s.Align }))
+	effective.KeepWithNext = ParseBool(first(func(s *Style) string { return s.KeepWithNext }))
+	effective.PageBreakBefore = ParseBool(first(func(s *Style) string { return s.PageBreakBefore }))
+	effective.Bold = ParseBool(first(func(s *Style) string { return s.Bold }))
+	effective.Italic = ParseBool(first(func(s *Style) string { return s.Italic }))
+	effective.Underline = ParseBool(first(func(s *Style) string { return s.Underline }))
+	// Fade In records all caps in bit one of effects
+	effective.AllCaps = ParseBool(first(func(s *Style) string {
+		if s.AllCaps == "" && s.Effects != "" {
+			if effects, err := strconv.Atoi(s.Effects); err == nil {
+				return strconv.Itoa(effects & 1)
+			}
+		}
+		return s.AllCaps
+	}))
+	return effective, err
+}
+
+// ResolveStyle resolves the named style as StyleOf does for a paragraph
+// using it without overrides
+func (document *OpenScreenplay) ResolveStyle(name string) (*EffectiveStyle, error) {
+	return document.StyleOf(&Para{Style: &Style{BaseStyleName: name}})
+}
@@ -0,0 +1,126 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStyleOf(t *testing.T) {
+	document, err := ParseFile(filepath.Join("testdata", "OSF-2.0.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Title is based on Action which is based on Normal Text
+	style, err := document.ResolveStyle("Title")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := strings.Join(style.Chain, ", "); s != "Title, Action, Normal Text" {
+		t.Errorf("unexpected chain %s", s)
+	}
+	if style.Align != CenterAlignment || !style.Bold || !style.Underline || style.Italic || !style.KeepWithNext ||
+		style.SpaceBefore != 0 || style.Font != "Courier Screenplay" || style.Size != 12 {
+		t.Errorf("unexpected Title style %+v", style)
+	}
+
+	// The paragraph's own style overrides the named style
+	para := &Para{Style: &Style{BaseStyleName: "Title", Align: "right", Bold: "0", PageBreakBefore: "1"}}
+	if style, err = document.StyleOf(para); err != nil {
+		t.Fatal(err)
+	}
+	if style.Name != "Title" || style.Align != RightAlignment || style.Bold || !style.Underline || !style.PageBreakBefore {
+		t.Errorf("unexpected overridden Title style %+v", style)
+	}
+
+	// A document without styles uses the built in ones, a paragraph
+	// without a style is Normal Text
+	document = NewOpenScreenplay20()
+	document.Styles = nil
+	if style, err = document.ResolveStyle(TransitionType); err != nil {
+		t.Fatal(err)
+	}
+	if style.Align != RightAlignment || style.LeftIndent != 1016 || style.RightIndent != 127 || !style.AllCaps {
+		t.Errorf("unexpected built in Transition style %+v", style)
+	}
+	if style, err = document.StyleOf(new(Para)); err != nil || style.Name != GeneralType || style.Align != LeftAlignment {
+		t.Errorf("expected Normal Text, got %+v, %v", style, err)
+	}
+
+	// A document's own styles are resolved before the built in ones,
+	// Dialogue gets the document's Normal Text font and size
+	document.Styles = &Styles{Style: []*Style{
+		{Name: GeneralType, Font: "Courier Prime", Size: "11"},
+		{Name: DialogueType, BaseStyleName: GeneralType},
+	}}
+	if style, err = document.ResolveStyle(DialogueType); err != nil {
+		t.Fatal(err)
+	}
+	if style.Font != "Courier Prime" || style.Size != 11 || style.LeftIndent != 0 || style.RightIndent != 0 {
+		t.Errorf("expected the document's Dialogue and Normal Text, got %+v", style)
+	}
+	// A style the document doesn't define is built in
+	if style, err = document.ResolveStyle(CharacterType); err != nil || style.LeftIndent != 635 || strings.Join(style.Chain, ", ") != "Character, Normal Text" {
+		t.Errorf("expected the built in Character, got %+v, %v", style, err)
+	}
+
+	// Fade In records all caps in effects
+	document.Styles = &Styles{Style: []*Style{
+		{Name: "Montage", BaseStyleName: ActionType, Effects: "1"},
+		{Name: "Insert", BaseStyleName: "Missing", Align: "center"},
+		{Name: "Loop A", BaseStyleName: "Loop B", Bold: "1"},
+		{Name: "Loop B", BaseStyleName: "Loop A", Italic: "1"},
+	}}
+	if style, err = document.ResolveStyle("Montage"); err != nil || !style.AllCaps || style.SpaceBefore != 1 {
+		t.Errorf("unexpected Montage style %+v, %v", style, err)
+	}
+
+	// Missing styles and loops are errors but still resolve
+	if _, err = document.ResolveStyle("Unknown"); err == nil || err.Error() != `style "Unknown" is not defined` {
+		t.Errorf("expected an undefined style error, got %v", err)
+	}
+	style, err = document.ResolveStyle("Insert")
+	if err == nil || err.Error() != `style "Insert" is based on "Missing" which is not defined` {
+		t.Errorf("expected a missing base error, got %v", err)
+	}
+	if style.Align != CenterAlignment {
+		t.Errorf("expected Insert to be resolved, got %+v", style)
+	}
+	style, err = document.ResolveStyle("Loop A")
+	if err == nil || err.Error() != `style "Loop A" is based on itself, Loop A -> Loop B -> Loop A` {
+		t.Errorf("expected a loop error, got %v", err)
+	}
+	if !style.Bold || !style.Italic {
+		t.Errorf("expected Loop A to be resolved, got %+v", style)
+	}
+}
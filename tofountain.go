@@ -0,0 +1,345 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"strconv"
+	"strings"
+)
+
+// fountainTitleKeys lists the Fountain title page keys written by
+// ToFountain along with the bookmark FromFountain gives each paragraph.
+var fountainTitleKeys = []struct {
+	Key      string
+	Bookmark string
+}{
+	{"Title", "Title"},
+	{"Credit", "Credits"},
+	{"Author", "Author"},
+	{"Source", "Source"},
+	{"Story By", "Story By"},
+	{"Draft date", "Drafts"},
+	{"Contact", "Contact"},
+	{"Copyright", "Copyright"},
+}
+
+// fountainEscape escapes characters Fountain would treat as emphasis
+func fountainEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "*", `\*`)
+	return strings.ReplaceAll(s, "_", `\_`)
+}
+
+// fountainEmphasis wraps a single line of text in Fountain emphasis
+// markers, leading and trailing spaces are kept outside the markers.
+func fountainEmphasis(text *Text, s string) string {
+	core := strings.TrimSpace(s)
+	if core == "" {
+		return s
+	}
+	i := strings.Index(s, core)
+	lead, trail := s[:i], s[i+len(core):]
+	bold, italic := text.Bold == BoldStyle, text.Italic == ItalicStyle
+	switch {
+	case bold && italic:
+		core = "***" + core + "***"
+	case bold:
+		core = "**" + core + "**"
+	case italic:
+		core = "*" + core + "*"
+	}
+	if text.Underline == UnderlineStyle {
+		core = "_" + core + "_"
+	}
+	return lead + core + trail
+}
+
+// fountainText renders a paragraph's text runs with Fountain emphasis.
+// Fountain has no strikethrough so it is dropped, all caps is applied
+// to the text.
+func fountainText(para *Para) string {
+	parts := []string{}
+	for _, text := range para.Text {
+		s := fountainEscape(text.InnerText)
+		if text.AllCaps == AllCapsStyle {
+			s = strings.ToUpper(s)
+		}
+		// Emphasis can't span a line break so each line is wrapped
+		lines := strings.Split(s, "\n")
+		for i, line := range lines {
+			lines[i] = fountainEmphasis(text, line)
+		}
+		parts = append(parts, strings.Join(lines, "\n"))
+	}
+	return strings.Join(parts, "")
+}
+
+// fountainNote renders a paragraph's note as a Fountain "[[note]]",
+// notes can't hold blank lines so they are dropped
+func fountainNote(note string) string {
+	lines := []string{}
+	for _, line := range strings.Split(note, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "[[" + strings.Join(lines, "\n") + "]]"
+}
+
+// fountainIsSceneHeading reports if a line starts like a scene heading,
+// INT, EXT, EST, INT/EXT or I/E followed by a "." or a space
+func fountainIsSceneHeading(line string) bool {
+	line = strings.ToUpper(strings.TrimSpace(line))
+	for _, prefix := range []string{"INT", "EXT", "EST", "INT/EXT", "I/E"} {
+		if rest, ok := strings.CutPrefix(line, prefix); ok && (strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, " ")) {
+			return true
+		}
+	}
+	return false
+}
+
+// fountainNeedsForcedAction reports if an action line could be read as
+// some other element. Line is the first line of the paragraph when first
+// is true, only that line can be mistaken for a character name.
+func fountainNeedsForcedAction(line string, first bool) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return false
+	}
+	upper := strings.ToUpper(trimmed)
+	if first && trimmed == upper {
+		return true
+	}
+	for _, prefix := range []string{"!", "@", ".", ">", "~", "=", "#", "[[", "/*", "(", "INT", "EXT", "EST", "I/E"} {
+		if strings.HasPrefix(upper, prefix) {
+			return true
+		}
+	}
+	switch upper {
+	case "FADE IN:", "THE END", "THE END.", "LA FIN", "LA FIN.":
+		return true
+	}
+	return strings.Contains(trimmed, " -") || strings.HasSuffix(trimmed, "#") ||
+		strings.HasSuffix(trimmed, "TO:") || strings.HasSuffix(trimmed, "IN:")
+}
+
+// fountainNeedsForcedCharacter reports if a character name must be
+// forced with @, e.g. it has lower case letters
+func fountainNeedsForcedCharacter(name string) bool {
+	upper := strings.ToUpper(name)
+	// Lines starting like a scene heading are forced even without the "."
+	// or space after the prefix, FromFountain's parser reads INT, EXT and
+	// I/E lines as scene headings regardless
+	for _, prefix := range []string{"INT", "EXT", "EST", "I/E"} {
+		if strings.HasPrefix(upper, prefix) {
+			return true
+		}
+	}
+	return name != upper || strings.HasSuffix(upper, ":") || strings.Contains(upper, "--") ||
+		strings.HasPrefix(upper, "(") || strings.HasPrefix(upper, "!")
+}
+
+// fountainTitlePage returns the Fountain title page key/value lines
+func (document *OpenScreenplay) fountainTitlePage() []string {
+	values := map[string]string{}
+	if document.TitlePage != nil {
+		for _, para := range document.TitlePage.Para {
+			for _, item := range fountainTitleKeys {
+				if para.Bookmark == item.Bookmark && strings.TrimSpace(para.PlainText()) != "" {
+					values[item.Key] = strings.TrimSpace(fountainText(para))
+				}
+			}
+		}
+	}
+	if info := document.Info; info != nil {
+		for key, value := range map[string]string{
+			"Title":      info.Title,
+			"Author":     info.WrittenBy,
+			"Draft date": info.Drafts,
+			"Contact":    info.Contact,
+			"Copyright":  info.Copyright,
+		} {
+			if _, ok := values[key]; !ok && strings.TrimSpace(value) != "" {
+				values[key] = fountainEscape(strings.TrimSpace(value))
+			}
+		}
+	}
+	lines := []string{}
+	for _, item := range fountainTitleKeys {
+		value, ok := values[item.Key]
+		if !ok {
+			continue
+		}
+		if strings.Contains(value, "\n") {
+			lines = append(lines, item.Key+":")
+			for _, line := range strings.Split(value, "\n") {
+				lines = append(lines, "\t"+strings.TrimSpace(line))
+			}
+		} else {
+			lines = append(lines, item.Key+": "+value)
+		}
+	}
+	return lines
+}
+
+// ToFountain renders the document as Fountain (https://fountain.io)
+// plain text. Elements that Fountain would otherwise guess wrong are
+// forced, e.g. "!" for action, "@" for character and "." for scene
+// headings, transitions always use ">".
+func (document *OpenScreenplay) ToFountain() string {
+	lines := document.fountainTitlePage()
+	if len(lines) > 0 {
+		lines = append(lines, "")
+	}
+
+	body := []string{}
+	prevStyle := ""
+	dualPending := false
+	// trailer holds the synopsis and note lines of a dialogue block, they
+	// follow the block since a blank line would end it.
+	trailer := []string{}
+	flush := func() {
+		if len(trailer) > 0 {
+			body = append(body, "")
+			body = append(body, trailer...)
+			trailer = []string{}
+		}
+	}
+	if document.Paragraphs != nil {
+		for _, para := range document.Paragraphs.Para {
+			if strings.TrimSpace(para.PlainText()) == "" {
+				continue
+			}
+			style, align := ActionType, ""
+			if para.Style != nil {
+				style, align = para.Style.BaseStyleName, para.Style.Align
+			}
+			text := fountainText(para)
+			// The first line of a script without a title page mustn't
+			// read as a title page key, e.g. "Note: ..."
+			titleKey := len(lines) == 0 && len(body) == 0 && strings.Contains(strings.SplitN(text, "\n", 2)[0], ":")
+			inDialogue := (style == ParentheticalType || style == DialogueType) &&
+				(prevStyle == CharacterType || prevStyle == ParentheticalType || prevStyle == DialogueType)
+			if !inDialogue {
+				flush()
+			}
+			if para.Style != nil && para.Style.PageBreakBefore == "1" && len(body) > 0 {
+				body = append(body, "", "===")
+				inDialogue = false
+			}
+			if !inDialogue && len(body) > 0 {
+				body = append(body, "")
+			}
+			switch style {
+			case SceneHeadingType:
+				text = strings.TrimSpace(text)
+				if !fountainIsSceneHeading(text) {
+					text = "." + text
+				}
+				if para.SceneNumber != "" {
+					text = text + " #" + para.SceneNumber + "#"
+				}
+				body = append(body, text)
+			case CharacterType:
+				text = strings.TrimSpace(text)
+				if fountainNeedsForcedCharacter(text) || titleKey {
+					text = "@" + text
+				}
+				if para.Style.DualDialogue == "1" {
+					dualPending = true
+				} else if dualPending {
+					text = text + " ^"
+					dualPending = false
+				}
+				body = append(body, text)
+			case ParentheticalType:
+				text = strings.TrimSpace(text)
+				if !strings.HasPrefix(text, "(") {
+					text = "(" + text + ")"
+				}
+				body = append(body, strings.Split(text, "\n")...)
+			case DialogueType:
+				for _, line := range strings.Split(text, "\n") {
+					if strings.TrimSpace(line) == "" {
+						// A blank line inside dialogue is two spaces
+						line = "  "
+					}
+					body = append(body, line)
+				}
+			case TransitionType:
+				body = append(body, "> "+strings.TrimSpace(text))
+			case SingingType:
+				for _, line := range strings.Split(text, "\n") {
+					body = append(body, "~"+line)
+				}
+			case SectionType:
+				level, _ := strconv.Atoi(para.Level)
+				if level < 1 {
+					level = 1
+				}
+				body = append(body, strings.Repeat("#", level)+" "+strings.TrimSpace(para.PlainText()))
+			case BoneyardType:
+				body = append(body, "/*")
+				body = append(body, strings.Split(para.PlainText(), "\n")...)
+				body = append(body, "*/")
+			default:
+				for i, line := range strings.Split(text, "\n") {
+					switch {
+					case strings.ToLower(align) == "center":
+						line = ">" + strings.TrimSpace(line) + "<"
+					case fountainNeedsForcedAction(line, i == 0), i == 0 && titleKey:
+						line = "!" + line
+					}
+					body = append(body, line)
+				}
+			}
+			for _, line := range strings.Split(para.Synopsis, "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					trailer = append(trailer, "= "+line)
+				}
+			}
+			if note := fountainNote(para.Note); note != "" {
+				trailer = append(trailer, note)
+			}
+			switch style {
+			case CharacterType, ParentheticalType, DialogueType:
+			default:
+				flush()
+			}
+			prevStyle = style
+		}
+	}
+	flush()
+	lines = append(lines, body...)
+	return strings.Join(lines, "\n") + "\n"
+}
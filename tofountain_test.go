@@ -0,0 +1,186 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	// My Packages
+	"github.com/rsdoiel/fountain"
+)
+
+func TestToFountain(t *testing.T) {
+	document, err := ParseFile(filepath.Join("testdata", "sample-01.osf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `> Fade in:
+
+Ext. Library - day
+
+[[sample one, basic scene]]
+
+A PROGRAMMER typing at an old laptop
+
+@Programmer
+(excited)
+Eureka!
+
+> Fade to black.
+`
+	if got := document.ToFountain(); !strings.HasSuffix(got, expected) {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+
+	// OSF to Fountain and back should give the same paragraphs, as far
+	// as Fountain has them, and title page
+	paraLines := func(document *OpenScreenplay) []string {
+		lines := []string{}
+		for _, para := range document.Paragraphs.Para {
+			if strings.TrimSpace(para.PlainText()) == "" {
+				continue
+			}
+			pageBreak, dual := "", ""
+			if para.Style != nil {
+				pageBreak, dual = para.Style.PageBreakBefore, para.Style.DualDialogue
+			}
+			lines = append(lines, strings.Join([]string{fountainStyle(para), para.SceneNumber, pageBreak, dual,
+				strings.TrimSpace(fountainText(para)), para.Note, para.Synopsis}, "|"))
+		}
+		return lines
+	}
+	fnames, _ := filepath.Glob(filepath.Join("testdata", "sample-*.osf"))
+	for _, fname := range fnames {
+		original, err := ParseFile(fname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		screenplay, err := fountain.Parse([]byte(original.ToFountain()))
+		if err != nil {
+			t.Errorf("%s, %s", fname, err)
+			continue
+		}
+		document := NewOpenScreenplay20()
+		document.FromFountain(screenplay)
+		if expected, got := strings.Join(paraLines(original), "\n"), strings.Join(paraLines(document), "\n"); expected != got {
+			t.Errorf("%s, expected\n%s\ngot\n%s", fname, expected, got)
+		}
+		if expected, got := strings.Join(original.fountainTitlePage(), "\n"), strings.Join(document.fountainTitlePage(), "\n"); expected != got {
+			t.Errorf("%s, expected title page\n%s\ngot\n%s", fname, expected, got)
+		}
+	}
+
+	para := func(style string, text ...*Text) *Para {
+		return &Para{Style: &Style{BaseStyleName: style}, Text: text}
+	}
+	plain := func(s string) *Text {
+		return &Text{InnerText: s}
+	}
+	document = NewOpenScreenplay20()
+	document.Info = &Info{Title: "Night Shift", WrittenBy: "Jane Doe"}
+	document.Paragraphs = &Paragraphs{Para: []*Para{
+		para(SceneHeadingType, plain("Rooftop")),
+		para(SceneHeadingType, plain("INTERCUT - KITCHEN")),
+		para(SceneHeadingType, plain("ESTATE - DAY")),
+		para(SceneHeadingType, plain("EXTRA ROOM")),
+		para(SceneHeadingType, plain("INT/EXT. CAR - NIGHT")),
+		para(SceneHeadingType, plain("EST. CITY - DAWN")),
+		para(ActionType, plain("INTERIOR DESIGN - the big idea.")),
+		para(ActionType, plain("She is "), &Text{InnerText: "very", Bold: BoldStyle}, plain(" 2*3_4.")),
+		para(CharacterType, plain("ANN")),
+		para(DialogueType, plain("No!")),
+		para(CharacterType, plain("Bob")),
+		para(DialogueType, plain("Yes!")),
+		para(TransitionType, plain("CUT TO:")),
+		para(SingingType, plain("La la la")),
+	}}
+	document.Paragraphs.Para[0].SceneNumber = "1A"
+	document.Paragraphs.Para[8].Style.DualDialogue = "1"
+	document.Paragraphs.Para[10].Style.PageBreakBefore = "1"
+	expected = `Title: Night Shift
+Author: Jane Doe
+
+.Rooftop #1A#
+
+.INTERCUT - KITCHEN
+
+.ESTATE - DAY
+
+.EXTRA ROOM
+
+INT/EXT. CAR - NIGHT
+
+EST. CITY - DAWN
+
+!INTERIOR DESIGN - the big idea.
+
+She is **very** 2\*3\_4.
+
+ANN
+No!
+
+===
+
+@Bob ^
+Yes!
+
+> CUT TO:
+
+~La la la
+`
+	if got := document.ToFountain(); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+	// Without a title page the script starts right away, a first line
+	// that could be a title page key is forced
+	document = NewOpenScreenplay20()
+	document.Paragraphs = &Paragraphs{Para: []*Para{
+		para(ActionType, plain("Note: nobody sleeps.")),
+		para(SceneHeadingType, plain("EXT. ALLEY - NIGHT")),
+	}}
+	expected = `!Note: nobody sleeps.
+
+EXT. ALLEY - NIGHT
+`
+	if got := document.ToFountain(); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+	screenplay, err := fountain.Parse([]byte(expected))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := NewOpenScreenplay20()
+	result.FromFountain(screenplay)
+	if expected, got := strings.Join(paraLines(document), "\n"), strings.Join(paraLines(result), "\n"); expected != got || result.TitlePage != nil {
+		t.Errorf("expected\n%s\nand no title page, got\n%s\n%+v", expected, got, result.TitlePage)
+	}
+}
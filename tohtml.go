@@ -0,0 +1,319 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// HTMLStylesheet is the default stylesheet for ToHTML, it follows the
+// Scrippets (https://fountain.io/scrippets) look. Widths and indents are
+// in characters of a monospace font, ten to the inch.
+const HTMLStylesheet = `.screenplay {
+  font-family: "Courier Prime", "Courier Screenplay", "Courier New", Courier, monospace;
+  font-size: 12pt;
+  line-height: 1.2;
+  max-width: 60ch;
+  margin: 0 auto;
+  padding: 1em 2ch;
+  background: #fff;
+  color: #000;
+}
+.screenplay p { margin: 0; }
+.screenplay .title-page { text-align: center; margin-bottom: 4em; }
+.screenplay .title-page .title { margin-top: 4em; font-size: 1.2em; }
+.screenplay .title-page .contact,
+.screenplay .title-page .drafts,
+.screenplay .title-page .copyright { text-align: left; margin-top: 2em; }
+.screenplay .scene-heading { margin-top: 2em; text-transform: uppercase; position: relative; }
+.screenplay .scene-heading[data-scene-number]::before { content: attr(data-scene-number); position: absolute; right: 100%; margin-right: 3ch; }
+.screenplay .action,
+.screenplay .shot,
+.screenplay .cast-list,
+.screenplay .transition { margin-top: 1em; }
+.screenplay .shot,
+.screenplay .transition { text-transform: uppercase; }
+.screenplay .transition { text-align: right; }
+.screenplay .character { margin: 1em 0 0 27ch; text-transform: uppercase; }
+.screenplay .parenthetical { margin: 0 20ch 0 22ch; }
+.screenplay .dialogue,
+.screenplay .singing { margin: 0 10ch 0 15ch; }
+.screenplay .singing { font-style: italic; }
+.screenplay .center { text-align: center; }
+.screenplay .right { text-align: right; }
+.screenplay .all-caps { text-transform: uppercase; }
+.screenplay .page-break { page-break-before: always; }
+.screenplay .revised { position: relative; }
+.screenplay .revised::after { content: "*"; position: absolute; right: -2ch; top: 0; }
+.screenplay .revision-1 { background: #cce0ff; }
+.screenplay .revision-2 { background: #ffd6e7; }
+.screenplay .revision-3 { background: #ffffb3; }
+.screenplay .revision-4 { background: #d6f5d6; }
+.screenplay .revision-5 { background: #f5deb3; }
+.screenplay .revision-6 { background: #f0e6d2; }
+.screenplay .revision-7 { background: #ffc8b4; }
+.screenplay .revision-8 { background: #ffb3c1; }
+.screenplay .revision-9 { background: #e6d3b3; }
+.screenplay .mark { border-left: 2px solid #c00; }
+`
+
+// htmlClass turns a style or bookmark name into a CSS class or id, e.g.
+// "Scene Heading" becomes "scene-heading"
+func htmlClass(name string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && sb.Len() > 0 {
+				sb.WriteRune('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return sb.String()
+}
+
+// isRevised reports if a revision attribute marks a change
+func isRevised(revision string) bool {
+	revision = strings.TrimSpace(revision)
+	return revision != "" && revision != "0"
+}
+
+// htmlText renders a run of text that starts at offset in its paragraph,
+// marks are inserted at their offset. Marks at the end of the paragraph
+// are written by the last run.
+func htmlText(text *Text, offset int, marks []*Mark, last bool) string {
+	var sb strings.Builder
+	r := []rune(text.InnerText)
+	for i := 0; i <= len(r); i++ {
+		if i < len(r) || last {
+			for _, mark := range marks {
+				if at, err := strconv.Atoi(mark.At); err == nil && at == offset+i {
+					fmt.Fprintf(&sb, `<span class="mark revision-%s"></span>`, html.EscapeString(mark.Revision))
+				}
+			}
+		}
+		if i == len(r) {
+			break
+		}
+		if r[i] == '\n' {
+			sb.WriteString("<br>\n")
+		} else {
+			sb.WriteString(html.EscapeString(string(r[i])))
+		}
+	}
+	s := sb.String()
+	if s == "" {
+		return ""
+	}
+	if text.Strikethrough == StrikethroughStyle {
+		s = "<s>" + s + "</s>"
+	}
+	if text.Underline == UnderlineStyle {
+		s = "<u>" + s + "</u>"
+	}
+	if text.Italic == ItalicStyle {
+		s = "<em>" + s + "</em>"
+	}
+	if text.Bold == BoldStyle {
+		s = "<strong>" + s + "</strong>"
+	}
+	if text.AllCaps == AllCapsStyle {
+		s = `<span class="all-caps">` + s + `</span>`
+	}
+	if isRevised(text.Revision) {
+		s = fmt.Sprintf(`<span class="revision revision-%s">%s</span>`, html.EscapeString(text.Revision), s)
+	}
+	return s
+}
+
+// htmlWriter renders paragraphs keeping track of the ids used
+type htmlWriter struct {
+	sb     strings.Builder
+	ids    map[string]bool
+	scenes map[*Para]string
+}
+
+// id returns a unique id for a bookmark
+func (w *htmlWriter) id(bookmark string) string {
+	id := htmlClass(bookmark)
+	if id == "" {
+		return ""
+	}
+	unique := id
+	for i := 2; w.ids[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d", id, i)
+	}
+	w.ids[unique] = true
+	return unique
+}
+
+// para writes a paragraph as a <p> with a class for its style
+func (w *htmlWriter) para(para *Para, classes ...string) {
+	style := ""
+	if para.Style != nil {
+		style = para.Style.BaseStyleName
+		if align := normalizeAlign(para.Style.Align); align != LeftAlignment {
+			classes = append(classes, strings.ToLower(align))
+		}
+		if ParseBool(para.Style.PageBreakBefore) {
+			classes = append(classes, "page-break")
+		}
+	}
+	classes = append([]string{htmlClass(style)}, classes...)
+	marks := []*Mark{}
+	if para.Marks != nil {
+		marks = para.Marks.Mark
+	}
+	revision := 0
+	for _, mark := range marks {
+		if i, err := strconv.Atoi(mark.Revision); err == nil && i > revision {
+			revision = i
+		}
+	}
+	for _, text := range para.Text {
+		if i, err := strconv.Atoi(text.Revision); err == nil && i > revision {
+			revision = i
+		}
+	}
+	if revision > 0 {
+		classes = append(classes, "revised")
+	}
+	names := []string{}
+	for _, class := range classes {
+		if class != "" {
+			names = append(names, class)
+		}
+	}
+	w.sb.WriteString("<p")
+	if len(names) > 0 {
+		fmt.Fprintf(&w.sb, ` class="%s"`, strings.Join(names, " "))
+	}
+	if para.Bookmark != "" {
+		if id := w.id(para.Bookmark); id != "" {
+			fmt.Fprintf(&w.sb, ` id="%s"`, id)
+		}
+	}
+	if number := w.scenes[para]; number != "" {
+		fmt.Fprintf(&w.sb, ` data-scene-number="%s"`, html.EscapeString(number))
+	}
+	if revision > 0 {
+		fmt.Fprintf(&w.sb, ` data-revision="%d"`, revision)
+	}
+	w.sb.WriteString(">")
+	texts := para.Text
+	if style == ParentheticalType && !strings.HasPrefix(strings.TrimSpace(para.PlainText()), "(") {
+		texts = append([]*Text{{InnerText: "("}}, append(texts, &Text{InnerText: ")"})...)
+		// Marks count from the start of the text without the parenthesis
+		shifted := []*Mark{}
+		for _, mark := range marks {
+			if at, err := strconv.Atoi(mark.At); err == nil {
+				shifted = append(shifted, &Mark{At: strconv.Itoa(at + 1), Revision: mark.Revision})
+			}
+		}
+		marks = shifted
+	}
+	offset := 0
+	for i, text := range texts {
+		w.sb.WriteString(htmlText(text, offset, marks, i == len(texts)-1))
+		offset += len([]rune(text.InnerText))
+	}
+	w.sb.WriteString("</p>\n")
+}
+
+// ToHTMLFragment renders the screenplay as semantic HTML for embedding in
+// another page. The screenplay is a <div class="screenplay"> holding the
+// title page and script sections. Each paragraph is a <p> with a class
+// named for its style (e.g. "scene-heading", "dialogue"), bookmarks become
+// ids and revised text is highlighted with "revision-N" classes. Notes,
+// synopses, sections and boneyard are not printed, sections leave an
+// anchor for their bookmark. See HTMLStylesheet for the classes used.
+func (document *OpenScreenplay) ToHTMLFragment() string {
+	w := &htmlWriter{ids: map[string]bool{}, scenes: document.printedSceneNumbers()}
+	w.sb.WriteString("<div class=\"screenplay\">\n")
+	if document.TitlePage != nil && len(document.TitlePage.Para) > 0 {
+		w.sb.WriteString("<section class=\"title-page\">\n")
+		for _, para := range document.TitlePage.Para {
+			if strings.TrimSpace(para.PlainText()) == "" {
+				continue
+			}
+			title := &Para{Bookmark: para.Bookmark, Text: para.Text, Marks: para.Marks}
+			if para.Style != nil {
+				title.Style = &Style{Align: para.Style.Align}
+			}
+			w.para(title, htmlClass(para.Bookmark))
+		}
+		w.sb.WriteString("</section>\n")
+	}
+	w.sb.WriteString("<section class=\"script\">\n")
+	if document.Paragraphs != nil {
+		for _, para := range document.Paragraphs.Para {
+			switch {
+			case para.SectionLevel() > 0:
+				if id := w.id(para.Bookmark); id != "" {
+					fmt.Fprintf(&w.sb, "<a class=\"section\" id=\"%s\"></a>\n", id)
+				}
+			case para.IsStructural() || strings.TrimSpace(para.PlainText()) == "":
+			default:
+				w.para(para)
+			}
+		}
+	}
+	w.sb.WriteString("</section>\n</div>\n")
+	return w.sb.String()
+}
+
+// ToHTML renders the screenplay as a complete HTML page with the default
+// stylesheet embedded (see ToHTMLFragment and HTMLStylesheet)
+func (document *OpenScreenplay) ToHTML() string {
+	title := "Screenplay"
+	if document.Info != nil && strings.TrimSpace(document.Info.Title) != "" {
+		title = strings.TrimSpace(document.Info.Title)
+	}
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+%s</style>
+</head>
+<body>
+%s</body>
+</html>
+`, html.EscapeString(title), HTMLStylesheet, document.ToHTMLFragment())
+}
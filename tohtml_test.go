@@ -0,0 +1,104 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestToHTML(t *testing.T) {
+	document, err := ParseFile(filepath.Join("testdata", "sample-01.osf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fragment := document.ToHTMLFragment()
+	for _, expected := range []string{
+		`<div class="screenplay">`,
+		`<p class="transition">Fade in:</p>`,
+		`<p class="scene-heading">Ext. Library - day</p>`,
+		`<p class="character">Programmer</p>`,
+		`<p class="parenthetical">(excited)</p>`,
+		`<p class="dialogue">Eureka!</p>`,
+	} {
+		if !strings.Contains(fragment, expected) {
+			t.Errorf("expected %q in\n%s", expected, fragment)
+		}
+	}
+	if strings.Contains(fragment, "<html") || strings.Contains(fragment, "<style") {
+		t.Errorf("a fragment should not be a complete page\n%s", fragment)
+	}
+	page := document.ToHTML()
+	if !strings.HasPrefix(page, "<!DOCTYPE html>") || !strings.Contains(page, "<title>Untitled Screenplay</title>") ||
+		!strings.Contains(page, HTMLStylesheet) || !strings.Contains(page, fragment) {
+		t.Errorf("expected a complete page with the stylesheet\n%s", page)
+	}
+
+	document, err = ParseFile(filepath.Join("testdata", "OSF-2.0.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fragment = document.ToHTMLFragment()
+	for _, expected := range []string{
+		`<section class="title-page">`,
+		`<p class="title center" id="title"><u>Open Screenplay Format</u></p>`,
+		`<p class="action" id="bookmark">...following line&#39;s bookmark named &#34;Bookmark&#34;.</p>`,
+		`<p class="action"><strong>Bold text </strong><em>Italic text </em><u>Underlined text</u> <s>Strikethrough text</s></p>`,
+		`<p class="action revised" data-revision="1"><span class="revision revision-1">An element completely marked as a Blue revision.</span></p>`,
+		`the last <span class="mark revision-2"></span>word.</p>`,
+		`This is a line with a<br>`,
+	} {
+		if !strings.Contains(fragment, expected) {
+			t.Errorf("expected %q in\n%s", expected, fragment)
+		}
+	}
+
+	// Sections leave an anchor, boneyard and empty paragraphs are skipped
+	document = NewOpenScreenplay20()
+	document.Paragraphs = &Paragraphs{Para: []*Para{
+		NewSection("Act One", 1),
+		{Style: &Style{BaseStyleName: BoneyardType}, Text: []*Text{{InnerText: "Cut"}}},
+		{Style: &Style{BaseStyleName: ActionType}, Text: []*Text{{InnerText: "<Tom> & Jerry"}}},
+		{Style: &Style{BaseStyleName: ActionType}, Text: []*Text{{InnerText: " "}}},
+		{Bookmark: "Act One", Style: &Style{BaseStyleName: SceneHeadingType, PageBreakBefore: "1"}, Text: []*Text{{InnerText: "INT. HOUSE - DAY"}}},
+	}}
+	expected := `<div class="screenplay">
+<section class="script">
+<a class="section" id="act-one"></a>
+<p class="action">&lt;Tom&gt; &amp; Jerry</p>
+<p class="scene-heading page-break" id="act-one-2">INT. HOUSE - DAY</p>
+</section>
+</div>
+`
+	if fragment := document.ToHTMLFragment(); fragment != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, fragment)
+	}
+}
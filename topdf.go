@@ -0,0 +1,289 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// pdfFontSize is the size of the Courier used, 12 point Courier is
+	// ten characters per inch
+	pdfFontSize = 12.0
+	// pdfCharWidth is the width of a Courier character in points
+	pdfCharWidth = pdfFontSize * 0.6
+)
+
+// pdfFonts are the standard PDF Courier fonts, every PDF reader has them
+// so nothing is embedded. They're indexed by bold + 2 * italic.
+var pdfFonts = []string{"Courier", "Courier-Bold", "Courier-Oblique", "Courier-BoldOblique"}
+
+// pdfWinAnsi maps the characters outside Latin 1 WinAnsiEncoding has
+var pdfWinAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b, 'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// pdfString encodes s as a PDF literal string in WinAnsiEncoding,
+// characters it doesn't have become "?"
+func pdfString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('(')
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == '\t':
+			sb.WriteByte(' ')
+		case r >= ' ' && r < 0x7f:
+			sb.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&sb, "\\%03o", r)
+		default:
+			if b, ok := pdfWinAnsi[r]; ok {
+				fmt.Fprintf(&sb, "\\%03o", b)
+			} else {
+				sb.WriteByte('?')
+			}
+		}
+	}
+	sb.WriteByte(')')
+	return sb.String()
+}
+
+// pdfNumber formats a position in points
+func pdfNumber(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// pdfPage is the content stream of a page. Positions are in points from
+// the top left corner of the page, text is placed by its baseline.
+type pdfPage struct {
+	height  float64
+	content bytes.Buffer
+}
+
+// text writes s at x, y in Courier, underline and strikethrough are
+// drawn as lines. It returns the x position following the text.
+func (page *pdfPage) text(x, y float64, s string, bold, italic, underline, strikethrough bool) float64 {
+	end := x + float64(utf8.RuneCountInString(s))*pdfCharWidth
+	if s == "" {
+		return end
+	}
+	font := 1
+	if bold {
+		font++
+	}
+	if italic {
+		font += 2
+	}
+	fmt.Fprintf(&page.content, "BT /F%d %s Tf %s %s Td %s Tj ET\n",
+		font, pdfNumber(pdfFontSize), pdfNumber(x), pdfNumber(page.height-y), pdfString(s))
+	if underline {
+		page.rule(x, end, y+pdfFontSize*0.12)
+	}
+	if strikethrough {
+		page.rule(x, end, y-pdfFontSize*0.25)
+	}
+	return end
+}
+
+// rule draws a thin horizontal line from x1 to x2 at y
+func (page *pdfPage) rule(x1, x2, y float64) {
+	fmt.Fprintf(&page.content, "%s w %s %s m %s %s l S\n", pdfNumber(pdfFontSize*0.05),
+		pdfNumber(x1), pdfNumber(page.height-y), pdfNumber(x2), pdfNumber(page.height-y))
+}
+
+// pdfDocument writes the objects of a PDF file and its cross reference
+// table
+type pdfDocument struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+// object writes the next object
+func (pdf *pdfDocument) object(body string) {
+	pdf.offsets = append(pdf.offsets, pdf.buf.Len())
+	fmt.Fprintf(&pdf.buf, "%d 0 obj\n%s\nendobj\n", len(pdf.offsets), body)
+}
+
+// ToPDF renders the screenplay as a PDF laid out (see Layout) on its page
+// size and margins in 12 point Courier. The title page comes first, pages
+// after the first have the page header (e.g. "12.") at the top, scene
+// numbers are printed in the margins beside the scene headings and revised
+// lines are marked with an asterisk in the right margin. It uses the PDF
+// reader's built in Courier fonts so no fonts are embedded.
+func (document *OpenScreenplay) ToPDF(out io.Writer) error {
+	return document.writePDF(out, document.Layout(), true)
+}
+
+// ToRevisedPDF renders the pages with changes from revision on (see
+// RevisedPages) as ToPDF does, for handing out revised pages. It is an
+// error if no page was revised, a PDF needs at least one page.
+func (document *OpenScreenplay) ToRevisedPDF(out io.Writer, revision int) error {
+	pages := document.RevisedPages(revision)
+	if len(pages) == 0 {
+		return fmt.Errorf("no pages revised in revision %d or later", revision)
+	}
+	return document.writePDF(out, pages, false)
+}
+
+// writePDF writes laid out pages as a PDF, after the title page if
+// titlePage is true
+func (document *OpenScreenplay) writePDF(out io.Writer, layoutPages []*Page, titlePage bool) error {
+	settings := document.Settings
+	pageWidth, pageHeight := settings.PageSize()
+	top, _, left, right := settings.Margins()
+	width := textColumns(settings.TextWidth())
+	lineHeight := 72 / settings.LinesPerInch()
+	showRevisions := settings == nil || settings.ShowRevisions == "" || ParseBool(settings.ShowRevisions)
+	sceneNumbers := document.printedSceneNumbers()
+	leftScenes, rightScenes := settings.SceneNumberSides()
+
+	// baseline is the distance from the top of the page to a row's baseline
+	baseline := func(row int) float64 {
+		return top.Points() + (float64(row)+0.8)*lineHeight
+	}
+	newPage := func() *pdfPage {
+		return &pdfPage{height: pageHeight.Points()}
+	}
+
+	pages := []*pdfPage{}
+	if lines := document.textTitlePage(0, width, settings.LinesPerPage()); titlePage && len(lines) > 0 {
+		page := newPage()
+		for row, s := range lines {
+			page.text(left.Points(), baseline(row), s, false, false, false, false)
+		}
+		pages = append(pages, page)
+	}
+	styles := map[*Para]*EffectiveStyle{}
+	for _, layout := range layoutPages {
+		page := newPage()
+		if settings.showPageHeader(layout) {
+			header := alignText(settings.FormatPageNumber(layout.Number), width, settings.HeaderAlign())
+			page.text(left.Points(), baseline(-2), header, false, false, false, false)
+		}
+		for row, line := range layout.Lines {
+			if line.Para == nil {
+				continue
+			}
+			style := &EffectiveStyle{}
+			if line.Start >= 0 {
+				if _, ok := styles[line.Para]; !ok {
+					styles[line.Para], _ = document.StyleOf(line.Para)
+				}
+				style = styles[line.Para]
+			}
+			y := baseline(row)
+			s := line.String()
+			x := left.Points() + line.Indent.Points()
+			x += float64(utf8.RuneCountInString(alignText(s, textColumns(line.Width), line.Align))-utf8.RuneCountInString(s)) * pdfCharWidth
+			for _, text := range line.Text {
+				x = page.text(x, y, text.InnerText,
+					style.Bold || text.Bold == BoldStyle,
+					style.Italic || text.Italic == ItalicStyle,
+					style.Underline || text.Underline == UnderlineStyle,
+					text.Strikethrough == StrikethroughStyle)
+			}
+			// Scene numbers sit three characters out from the text
+			if number := sceneNumbers[line.Para]; number != "" && line.Start == 0 && line.Style == SceneHeadingType {
+				if leftScenes {
+					x := left.Points() + line.Indent.Points() - float64(utf8.RuneCountInString(number)+3)*pdfCharWidth
+					page.text(x, y, number, false, false, false, false)
+				}
+				if rightScenes {
+					x := left.Points() + settings.TextWidth().Points() + 3*pdfCharWidth
+					page.text(x, y, number, false, false, false, false)
+				}
+			}
+			// Revision marks sit half an inch from the right edge
+			if showRevisions && line.Revision() > 0 {
+				x := pageWidth.Points() - 36
+				if min := pageWidth.Points() - right.Points() + pdfCharWidth; x < min {
+					x = min
+				}
+				page.text(x, y, "*", false, false, false, false)
+			}
+		}
+		pages = append(pages, page)
+	}
+
+	// Objects 1 and 2 are the catalog and page tree, then the document
+	// information, the fonts and a page and its content for each page
+	pdf := new(pdfDocument)
+	pdf.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	first := 4 + len(pdfFonts)
+	kids := []string{}
+	for i := range pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", first+i*2))
+	}
+	pdf.object("<< /Type /Catalog /Pages 2 0 R >>")
+	pdf.object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	info := []string{"/Producer " + pdfString("osf "+Version)}
+	if document.Info != nil {
+		if document.Info.Title != "" {
+			info = append(info, "/Title "+pdfString(document.Info.Title))
+		}
+		if document.Info.WrittenBy != "" {
+			info = append(info, "/Author "+pdfString(document.Info.WrittenBy))
+		}
+	}
+	pdf.object("<< " + strings.Join(info, " ") + " >>")
+	fonts := []string{}
+	for i, name := range pdfFonts {
+		pdf.object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+		fonts = append(fonts, fmt.Sprintf("/F%d %d 0 R", i+1, 4+i))
+	}
+	for i, page := range pages {
+		pdf.object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s >> >> /Contents %d 0 R >>",
+			pdfNumber(pageWidth.Points()), pdfNumber(pageHeight.Points()), strings.Join(fonts, " "), first+i*2+1))
+		pdf.object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.content.Len(), page.content.String()))
+	}
+	xref := pdf.buf.Len()
+	fmt.Fprintf(&pdf.buf, "xref\n0 %d\n0000000000 65535 f \n", len(pdf.offsets)+1)
+	for _, offset := range pdf.offsets {
+		fmt.Fprintf(&pdf.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&pdf.buf, "trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(pdf.offsets)+1, xref)
+	_, err := out.Write(pdf.buf.Bytes())
+	return err
+}
@@ -0,0 +1,156 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// checkPDF checks the cross reference table points at each object and
+// returns the content streams of the pages
+func checkPDF(t *testing.T, src []byte) []string {
+	t.Helper()
+	if !bytes.HasPrefix(src, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(src, []byte("%%EOF\n")) {
+		t.Fatalf("expected a PDF file, got %q", src)
+	}
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(src)
+	if m == nil {
+		t.Fatalf("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(src[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d doesn't point at the xref table", xref)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(src[xref:], -1)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if expected := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(src[offset:], []byte(expected)) {
+			t.Errorf("xref entry %d doesn't point at %q", i+1, expected)
+		}
+	}
+	streams := []string{}
+	for _, m := range regexp.MustCompile(`(?s)<< /Length (\d+) >>\nstream\n(.*?)endstream`).FindAllSubmatch(src, -1) {
+		if l, _ := strconv.Atoi(string(m[1])); l != len(m[2]) {
+			t.Errorf("expected stream length %d, got %d", len(m[2]), l)
+		}
+		streams = append(streams, string(m[2]))
+	}
+	return streams
+}
+
+func TestToPDF(t *testing.T) {
+	for s, expected := range map[string]string{
+		"Eureka!":         "(Eureka!)",
+		"(cont'd) a\\b":   `(\(cont'd\) a\\b)`,
+		"Café “hi” — ok☃": `(Caf\351 \223hi\224 \227 ok?)`,
+	} {
+		if got := pdfString(s); got != expected {
+			t.Errorf("expected %s, got %s", expected, got)
+		}
+	}
+
+	document, err := ParseFile(filepath.Join("testdata", "Screenplay_Sample.osf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := document.ToPDF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	pages := checkPDF(t, buf.Bytes())
+	if len(pages) != len(document.Layout()) {
+		t.Fatalf("expected %d pages, got %d", len(document.Layout()), len(pages))
+	}
+	if !strings.Contains(buf.String(), "/MediaBox [0 0 612 792]") || !strings.Contains(buf.String(), "/BaseFont /Courier-Bold") {
+		t.Errorf("expected US Letter pages in Courier")
+	}
+	for _, expected := range []string{
+		"Td (                                                          1.) Tj ET\n",
+		"BT /F1 12 Tf 89.86 656.54 Td (INT. FIRST LOCATION - DAY) Tj ET\n",
+		"Td (FIRST CHARACTER) Tj ET\n",
+	} {
+		if !strings.Contains(pages[0], expected) {
+			t.Errorf("expected %q on the first page\n%s", expected, pages[0])
+		}
+	}
+
+	// Title page, scene numbers, formatting and revisions
+	document, err = ParseFile(filepath.Join("testdata", "OSF-2.0.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := document.ToPDF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	pages = checkPDF(t, buf.Bytes())
+	if len(pages) != len(document.Layout())+1 {
+		t.Fatalf("expected a title page and %d pages, got %d", len(document.Layout()), len(pages))
+	}
+	if !strings.Contains(pages[0], "Open Screenplay Format) Tj") || strings.Contains(pages[0], "(1.) Tj") {
+		t.Errorf("expected the title page without a header\n%s", pages[0])
+	}
+	if strings.Contains(pages[1], "1.) Tj") || !strings.Contains(pages[2], "2.) Tj") {
+		t.Errorf("expected a page header on the second page only\n%s", pages[2])
+	}
+	body := strings.Join(pages[1:], "")
+	for _, expected := range []string{
+		"BT /F2 12 Tf 89.86 440.54 Td (Bold text ) Tj ET\n",
+		"BT /F3 12 Tf 161.86 440.54 Td (Italic text ) Tj ET\n",
+		"0.6 w 248.26 439.1 m 356.26 439.1 l S\n",
+		"0.6 w 363.46 443.54 m 493.06 443.54 l S\n",
+		"BT /F1 12 Tf 89.86 272.54 Td (INT. FIRST LOCATION - DAY) Tj ET\nBT /F1 12 Tf 61.06 272.54 Td (1) Tj ET\nBT /F1 12 Tf 543.74 272.54 Td (1) Tj ET\n",
+		"BT /F1 12 Tf 576 692.54 Td (*) Tj ET\n",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected %q in\n%s", expected, body)
+		}
+	}
+	asterisks := strings.Count(body, "(*) Tj")
+	document.Settings.ShowRevisions = "false"
+	document.Settings.SetSceneNumberSides(true, false)
+	buf.Reset()
+	if err := document.ToPDF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	body = strings.Join(checkPDF(t, buf.Bytes())[1:], "")
+	if got := strings.Count(body, "(*) Tj"); asterisks == 0 || got != 0 {
+		t.Errorf("expected %d revision asterisks and none when revisions are hidden, got %d", asterisks, got)
+	}
+	if strings.Contains(body, "543.74 272.54 Td (1)") || !strings.Contains(body, "61.06 272.54 Td (1)") {
+		t.Errorf("expected the scene number in the left margin only")
+	}
+}
@@ -0,0 +1,180 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// textColumns converts a length to a number of monospace characters at
+// ten characters per inch
+func textColumns(l Length) int {
+	return int(math.Floor(l.Inches()*10 + 0.001))
+}
+
+// alignText pads s to sit in a column width characters wide
+func alignText(s string, width int, align string) string {
+	pad := 0
+	switch align {
+	case CenterAlignment:
+		pad = (width - utf8.RuneCountInString(s)) / 2
+	case RightAlignment:
+		pad = width - utf8.RuneCountInString(s)
+	}
+	if pad < 0 {
+		pad = 0
+	}
+	return strings.Repeat(" ", pad) + s
+}
+
+// textLine renders a laid out line indented by margin characters
+func textLine(line *Line, margin int) string {
+	s := line.String()
+	if s == "" {
+		return ""
+	}
+	return strings.Repeat(" ", margin+textColumns(line.Indent)) + alignText(s, textColumns(line.Width), line.Align)
+}
+
+// textSceneNumbers adds a scene number three characters out from a scene
+// heading that starts at column start and has its right edge at end
+func textSceneNumbers(s string, number string, start int, end int, left bool, right bool) string {
+	r := []rune(s)
+	n := []rune(number)
+	if col := start - len(n) - 3; left && col >= 0 && len(r) >= start {
+		copy(r[col:], n)
+	}
+	if right {
+		for len(r) < end+3 {
+			r = append(r, ' ')
+		}
+		r = append(r, n...)
+	}
+	return string(r)
+}
+
+// textTitlePage renders the title page, Title, Credits, Author, Source and
+// Story By are centered in the top half and the rest is at the bottom.
+func (document *OpenScreenplay) textTitlePage(margin int, width int, height int) []string {
+	if document.TitlePage == nil {
+		return nil
+	}
+	top, bottom := []string{}, []string{}
+	for _, para := range document.TitlePage.Para {
+		s := strings.TrimSpace(para.PlainText())
+		if s == "" {
+			continue
+		}
+		align := LeftAlignment
+		if para.Style != nil {
+			align = normalizeAlign(para.Style.Align)
+		}
+		lines := []string{}
+		for _, text := range wrapRuns([]*Text{{InnerText: s}}, width) {
+			t := (&Line{Text: text}).String()
+			lines = append(lines, strings.Repeat(" ", margin)+alignText(t, width, align))
+		}
+		switch para.Bookmark {
+		case "Title", "Credits", "Author", "Source", "Story By":
+			top = append(top, "")
+			top = append(top, lines...)
+		default:
+			bottom = append(bottom, "")
+			bottom = append(bottom, lines...)
+		}
+	}
+	if len(top) == 0 && len(bottom) == 0 {
+		return nil
+	}
+	// The title sits a third of the way down the page
+	lines := make([]string, height/3)
+	lines = append(lines, top...)
+	if gap := height - len(lines) - len(bottom); gap > 0 {
+		lines = append(lines, make([]string, gap)...)
+	}
+	return append(lines, bottom...)
+}
+
+// ToText renders the screenplay as plain text laid out on pages the way
+// it would be printed, elements are indented, wrapped and aligned using
+// their styles. Pages are separated by form feeds and start with the page
+// number, scene numbers are shown beside the scene headings. The page's
+// left margin is kept as long as the lines fit in MaxLineWidth characters.
+func (document *OpenScreenplay) ToText() string {
+	return document.textPages(document.Layout(), true)
+}
+
+// ToRevisedText renders the pages with changes from revision on (see
+// RevisedPages) as ToText does, for handing out revised pages
+func (document *OpenScreenplay) ToRevisedText(revision int) string {
+	return document.textPages(document.RevisedPages(revision), false)
+}
+
+// textPages renders laid out pages, after the title page if titlePage is
+// true
+func (document *OpenScreenplay) textPages(layout []*Page, titlePage bool) string {
+	settings := document.Settings
+	width := textColumns(settings.TextWidth())
+	_, _, left, _ := settings.Margins()
+	margin := textColumns(left)
+	if margin > MaxLineWidth-width {
+		margin = MaxLineWidth - width
+	}
+	if margin < 0 {
+		margin = 0
+	}
+
+	sceneNumbers := document.printedSceneNumbers()
+	leftScenes, rightScenes := settings.SceneNumberSides()
+	pages := []string{}
+	if titlePage {
+		if lines := document.textTitlePage(margin, width, settings.LinesPerPage()); len(lines) > 0 {
+			pages = append(pages, strings.Join(lines, "\n")+"\n")
+		}
+	}
+	for _, page := range layout {
+		lines := []string{}
+		if settings.showPageHeader(page) {
+			header := settings.FormatPageNumber(page.Number)
+			lines = append(lines, strings.Repeat(" ", margin)+alignText(header, width, settings.HeaderAlign()), "")
+		}
+		for _, line := range page.Lines {
+			s := textLine(line, margin)
+			if number := sceneNumbers[line.Para]; number != "" && line.Start == 0 && line.Style == SceneHeadingType {
+				s = textSceneNumbers(s, number, margin+textColumns(line.Indent), margin+width, leftScenes, rightScenes)
+			}
+			lines = append(lines, s)
+		}
+		pages = append(pages, strings.Join(lines, "\n")+"\n")
+	}
+	return strings.Join(pages, "\f")
+}
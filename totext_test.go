@@ -0,0 +1,103 @@
+// osf is a package for working with Open Screenplay Format 1.2, 2.0 and 2.1 XML documents.
+//
+// @author R. S. Doiel, <rsdoiel@gmail.com>
+//
+// # BSD 2-Clause License
+//
+// Copyright (c) 2021, R. S. Doiel
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+//   - Redistributions of source code must retain the above copyright notice, this
+//     list of conditions and the following disclaimer.
+//
+//   - Redistributions in binary form must reproduce the above copyright notice,
+//     this list of conditions and the following disclaimer in the documentation
+//     and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+// FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+// DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+// SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+// OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package osf
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestToText(t *testing.T) {
+	document, err := ParseFile(filepath.Join("testdata", "sample-01.osf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// US Letter with a 1.25 inch left margin is 12 characters of margin
+	// and 60 characters of text
+	expected := []string{
+		"                                                                      1.",
+		"",
+		"                                                                FADE IN:",
+		"",
+		"",
+		"            EXT. LIBRARY - DAY",
+		"",
+		"            A PROGRAMMER typing at an old laptop",
+		"",
+		"                                      PROGRAMMER",
+		"                                 (excited)",
+		"                           Eureka!",
+		"",
+		"                                                          FADE TO BLACK.",
+		"",
+	}
+	if s := document.ToText(); s != strings.Join(expected, "\n") {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(expected, "\n"), s)
+	}
+
+	para := func(style string, s string) *Para {
+		return &Para{Style: &Style{BaseStyleName: style}, Text: []*Text{{InnerText: s}}}
+	}
+	document = NewOpenScreenplay20()
+	document.TitlePage = &TitlePage{Para: []*Para{
+		{Bookmark: "Title", Style: &Style{Align: "center"}, Text: []*Text{{InnerText: "Night Shift"}}},
+		{Bookmark: "Contact", Text: []*Text{{InnerText: "jane@example.org"}}},
+	}}
+	document.Paragraphs = new(Paragraphs)
+	for i := 0; i < 40; i++ {
+		document.Paragraphs.Para = append(document.Paragraphs.Para, para(ActionType, strings.Repeat("Something happens. ", 5)))
+	}
+	pages := strings.Split(document.ToText(), "\f")
+	if len(pages) != 4 {
+		t.Fatalf("expected a title page and 3 pages, got %d", len(pages))
+	}
+	if !strings.Contains(pages[0], strings.Repeat(" ", 12+24)+"Night Shift\n") {
+		t.Errorf("expected a centered title, got\n%s", pages[0])
+	}
+	if strings.Contains(pages[1], "1.") {
+		t.Errorf("the first page should not be numbered\n%s", pages[1])
+	}
+	if !strings.HasPrefix(pages[2], strings.Repeat(" ", 70)+"2.\n\n") {
+		t.Errorf("expected page 2 to start with its number, got\n%s", pages[2])
+	}
+	for i, page := range pages {
+		lines := strings.Split(strings.TrimSuffix(page, "\n"), "\n")
+		if len(lines) > 55 {
+			t.Errorf("page %d has %d lines", i, len(lines))
+		}
+		for _, line := range lines {
+			if utf8.RuneCountInString(line) > MaxLineWidth {
+				t.Errorf("page %d, line longer than %d characters %q", i, MaxLineWidth, line)
+			}
+		}
+	}
+}